@@ -0,0 +1,93 @@
+package database
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config ค่าการเชื่อมต่อและ connection pool (ค่าเริ่มต้นดู ConfigFromEnv)
+type Config struct {
+	Driver           string
+	DSN              string        // primary (เขียน + อ่านที่ต้องการข้อมูลล่าสุด)
+	ReplicaDSNs      []string      // read replica (ว่าง = อ่านจาก primary)
+	StatementTimeout time.Duration // 0 = ไม่จำกัด
+
+	MaxOpenConns    int           // connection สูงสุด (0 = ไม่จำกัด)
+	MaxIdleConns    int           // connection ว่างที่เก็บไว้ใน pool
+	ConnMaxLifetime time.Duration // อายุสูงสุดของ connection (กัน LB/DB ตัดทิ้งเงียบๆ)
+	ConnMaxIdleTime time.Duration // ปิด connection ที่ว่างนานเกินนี้
+
+	ConnectAttempts   int           // จำนวนครั้งที่ลองเชื่อมต่อตอนเริ่ม
+	ConnectBackoff    time.Duration // เวลารอก่อนลองใหม่ครั้งแรก (เพิ่มเป็นสองเท่าทุกครั้ง)
+	ConnectMaxBackoff time.Duration // เวลารอสูงสุดระหว่างครั้ง
+
+	PingInterval time.Duration // ping เป็นระยะ (0 = ปิด)
+}
+
+// ConfigFromEnv อ่าน Config จาก env (ค่าที่ไม่ตั้งหรือผิดรูปแบบใช้ค่าเริ่มต้น)
+//
+//	DB_DRIVER, DB_DSN, DB_REPLICA_DSNS (คั่นด้วย ","), DB_STATEMENT_TIMEOUT
+//	DB_MAX_OPEN_CONNS (25), DB_MAX_IDLE_CONNS (10), DB_CONN_MAX_LIFETIME (30m), DB_CONN_MAX_IDLE_TIME (5m)
+//	DB_CONNECT_ATTEMPTS (5), DB_CONNECT_BACKOFF (500ms), DB_CONNECT_MAX_BACKOFF (10s)
+//	DB_PING_INTERVAL (30s)
+func ConfigFromEnv() Config {
+	return Config{
+		Driver:           driverFromEnv(),
+		DSN:              os.Getenv("DB_DSN"),
+		ReplicaDSNs:      listEnv("DB_REPLICA_DSNS"),
+		StatementTimeout: durationEnv("DB_STATEMENT_TIMEOUT", 0),
+
+		MaxOpenConns:    intEnv("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    intEnv("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: durationEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime: durationEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+
+		ConnectAttempts:   max(intEnv("DB_CONNECT_ATTEMPTS", 5), 1),
+		ConnectBackoff:    max(durationEnv("DB_CONNECT_BACKOFF", 500*time.Millisecond), time.Millisecond),
+		ConnectMaxBackoff: durationEnv("DB_CONNECT_MAX_BACKOFF", 10*time.Second),
+
+		PingInterval: durationEnv("DB_PING_INTERVAL", 30*time.Second),
+	}
+}
+
+// durationEnv อ่าน env แบบ time.ParseDuration — ค่าติดลบหรือผิดรูปแบบใช้ fallback
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		log.Printf("invalid %s=%q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// intEnv อ่าน env เป็นจำนวนเต็มไม่ติดลบ — ค่าผิดรูปแบบใช้ fallback
+func intEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("invalid %s=%q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// listEnv อ่าน env ที่คั่นด้วย "," (ตัดช่องว่างและค่าว่างทิ้ง)
+func listEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
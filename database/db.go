@@ -1,20 +1,199 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
-	"os"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
 )
 
-var DB *gorm.DB
+// Handle ฐานข้อมูลที่เปิดแล้ว ส่งต่อให้ repository/migrator แทนตัวแปร global
+// ถือ pool ของ database/sql (primary + read replica) และ goroutine ping เป็นระยะ — ต้องเรียก Close ตอนปิดโปรแกรม
+type Handle struct {
+	db     *gorm.DB
+	sqlDB  *sql.DB
+	driver string
+	config Config
+
+	replicas    []*replica
+	nextReplica atomic.Uint64
+
+	stopPing context.CancelFunc
+	pingDone chan struct{}
+
+	mutex       sync.RWMutex
+	lastPingErr error
+	lastPingAt  time.Time
+}
 
-func Connect() {
+// Connect อ่านค่าจาก env (ดู ConfigFromEnv) แล้วเปิดฐานข้อมูล
+func Connect(ctx context.Context) (*Handle, error) {
 	_ = godotenv.Load()
-	dsn := os.Getenv("DB_DSN")
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil { log.Fatal("failed to connect database: ", err) }
-	DB = db
+	return Open(ctx, ConfigFromEnv())
+}
+
+// Open เปิดฐานข้อมูลตาม config: ลองเชื่อมต่อซ้ำแบบ exponential backoff ถ้า DB ยังไม่พร้อม
+// (เช่น container ของ DB ขึ้นช้ากว่า app) ตั้งค่า pool แล้วเริ่ม ping เป็นระยะ
+func Open(ctx context.Context, config Config) (*Handle, error) {
+	db, err := openWithRetry(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := usePlugins(db); err != nil {
+		closeDB(db)
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		closeDB(db)
+		return nil, err
+	}
+	configurePool(sqlDB, config)
+
+	replicas := openReplicas(ctx, config)
+	handle := &Handle{db: db, sqlDB: sqlDB, driver: config.Driver, config: config, replicas: replicas, lastPingAt: time.Now()}
+	if config.PingInterval > 0 {
+		pingContext, stop := context.WithCancel(context.Background())
+		handle.stopPing = stop
+		handle.pingDone = make(chan struct{})
+		go handle.pingLoop(pingContext, config.PingInterval)
+	}
+	return handle, nil
+}
+
+// usePlugins ปลั๊กอินที่ทั้ง primary และ replica ใช้: comment request ID หน้า SQL, จับเวลาคำสั่งลง metrics และ span ต่อคำสั่ง
+func usePlugins(db *gorm.DB) error {
+	if err := db.Use(QueryComment{}); err != nil {
+		return fmt.Errorf("register query comment plugin: %w", err)
+	}
+	if err := db.Use(QueryMetrics{}); err != nil {
+		return fmt.Errorf("register query metrics plugin: %w", err)
+	}
+	if err := db.Use(QueryTracing{}); err != nil {
+		return fmt.Errorf("register query tracing plugin: %w", err)
+	}
+	return nil
+}
+
+// closeDB ปิด pool ของ db ที่เปิดไปแล้วเมื่อขั้นถัดไปของการเปิดล้ม ไม่ให้ connection ค้าง
+// db.DB() ล้มได้เมื่อ ConnPool ไม่ใช่ *sql.DB — กรณีนั้นปิด ConnPool ตรงๆ ถ้าปิดได้
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+		return
+	}
+	if closer, ok := db.ConnPool.(interface{ Close() error }); ok {
+		_ = closer.Close()
+	}
+}
+
+func configurePool(sqlDB *sql.DB, config Config) {
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+}
+
+// openWithRetry ลอง gorm.Open (ซึ่ง ping ให้ด้วย) สูงสุด ConnectAttempts ครั้ง
+// รอ ConnectBackoff, 2x, 4x ... ไม่เกิน ConnectMaxBackoff (บวก jitter กันหลาย replica ยิงพร้อมกัน)
+func openWithRetry(ctx context.Context, config Config) (*gorm.DB, error) {
+	dialector, err := openDialector(config.Driver, config.DSN, config.StatementTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := config.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		// TranslateError: แปลง error ของ driver เป็น gorm.ErrDuplicatedKey ฯลฯ ให้ repository แยกชนิดได้
+		db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= config.ConnectAttempts {
+			return nil, fmt.Errorf("connect database (%d attempts): %w", attempt, err)
+		}
+
+		wait := backoff + rand.N(backoff/2+1)
+		log.Printf("connect database failed (attempt %d/%d), retrying in %s: %v", attempt, config.ConnectAttempts, wait.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect database: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, config.ConnectMaxBackoff)
+	}
+}
+
+// pingLoop ping ทุก interval เก็บผลล่าสุดไว้ให้ health check และ log เมื่อสถานะเปลี่ยน
+func (handle *Handle) pingLoop(ctx context.Context, interval time.Duration) {
+	defer close(handle.pingDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingContext, cancel := context.WithTimeout(ctx, interval)
+			err := handle.Ping(pingContext)
+			cancel()
+
+			handle.mutex.Lock()
+			previous := handle.lastPingErr
+			handle.lastPingErr, handle.lastPingAt = err, time.Now()
+			handle.mutex.Unlock()
+
+			switch {
+			case err != nil && previous == nil:
+				logger.Warnf("database", "ping failed: %v", err)
+			case err == nil && previous != nil:
+				logger.Infof("database", "ping recovered")
+			}
+
+			handle.checkReplicas(ctx, interval)
+		}
+	}
+}
+
+// DB คืน *gorm.DB ของ primary สำหรับเขียน / migrator (อ่านผ่าน replica ใช้ Reader)
+func (handle *Handle) DB() *gorm.DB { return handle.db }
+
+// Driver ชื่อ driver ที่ใช้อยู่ (postgres | mysql | sqlite)
+func (handle *Handle) Driver() string { return handle.driver }
+
+// Ping ตรวจว่ายังคุยกับฐานข้อมูลได้
+func (handle *Handle) Ping(ctx context.Context) error { return handle.sqlDB.PingContext(ctx) }
+
+// LastPing ผลของ ping เป็นระยะครั้งล่าสุด (err = nil คือปกติ)
+func (handle *Handle) LastPing() (at time.Time, err error) {
+	handle.mutex.RLock()
+	defer handle.mutex.RUnlock()
+	return handle.lastPingAt, handle.lastPingErr
+}
+
+// Stats สถิติของ connection pool (เปิดอยู่/ใช้งาน/ว่าง/รอ connection) สำหรับ metrics
+func (handle *Handle) Stats() sql.DBStats { return handle.sqlDB.Stats() }
+
+// Close หยุด ping แล้วปิด pool ทั้ง replica และ primary (รอ query ที่ค้างอยู่จนเสร็จ) เรียกซ้ำได้
+func (handle *Handle) Close() error {
+	if handle.stopPing != nil {
+		handle.stopPing()
+		<-handle.pingDone
+	}
+	for _, member := range handle.replicas {
+		if sqlDB := member.sqlDB(); sqlDB != nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return handle.sqlDB.Close()
 }
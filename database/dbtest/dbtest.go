@@ -0,0 +1,90 @@
+// Package dbtest เปิดฐานข้อมูลจริงที่รัน migration แล้วสำหรับเทสต์ (ใช้จากไฟล์ _test.go เท่านั้น)
+//
+// SQLite รันได้เสมอ (ไฟล์ชั่วคราว) ส่วน Postgres/MySQL รันเมื่อตั้ง DSN ของฐานข้อมูลสำหรับเทสต์ไว้
+//
+//	TEST_POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=books_test sslmode=disable"
+//	TEST_MYSQL_DSN="root:root@tcp(localhost:3306)/books_test?parseTime=true"
+//
+// ฐานข้อมูลเหล่านี้ถูกล้างตาราง books และ idempotency_keys ก่อนทุกเทสต์ — อย่าชี้ไปที่ฐานข้อมูลจริง
+package dbtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/migrations"
+)
+
+// OpenSQLite ไฟล์ SQLite ใหม่ใน t.TempDir() ที่รัน migration ครบแล้ว ปิดเองเมื่อเทสต์จบ
+func OpenSQLite(t testing.TB) *database.Handle {
+	t.Helper()
+	return open(t, database.DriverSQLite, filepath.Join(t.TempDir(), "books.db"))
+}
+
+// dsnEnv env ที่เก็บ DSN ของแต่ละ driver (SQLite ใช้ไฟล์ชั่วคราวจึงไม่มี)
+var dsnEnv = map[string]string{
+	database.DriverPostgres: "TEST_POSTGRES_DSN",
+	database.DriverMySQL:    "TEST_MYSQL_DSN",
+}
+
+// ForEachDriver รัน fn เป็น subtest ต่อ driver ด้วยฐานข้อมูลว่างที่รัน migration แล้ว
+// driver ที่ไม่ได้ตั้ง DSN ถูก skip (ดูชื่อ env ในคำอธิบาย package)
+func ForEachDriver(t *testing.T, fn func(t *testing.T, handle *database.Handle)) {
+	t.Helper()
+	for _, driver := range database.Drivers {
+		t.Run(driver, func(t *testing.T) {
+			fn(t, Open(t, driver))
+		})
+	}
+}
+
+// Open ฐานข้อมูลว่างของ driver ที่รัน migration แล้ว — skip ถ้า driver นั้นไม่ได้ตั้ง DSN สำหรับเทสต์
+func Open(t testing.TB, driver string) *database.Handle {
+	t.Helper()
+	if driver == database.DriverSQLite {
+		return OpenSQLite(t)
+	}
+	dsn := os.Getenv(dsnEnv[driver])
+	if dsn == "" {
+		t.Skipf("%s not set", dsnEnv[driver])
+	}
+	handle := open(t, driver, dsn)
+	for _, table := range []string{"books", "idempotency_keys"} {
+		if err := handle.DB().Exec("DELETE FROM " + table).Error; err != nil {
+			t.Fatalf("clean %s: %v", table, err)
+		}
+	}
+	return handle
+}
+
+// open เปิดฐานข้อมูลด้วย pool ขนาดปกติ (ไม่ ping เป็นระยะ) แล้วรัน migration
+func open(t testing.TB, driver, dsn string) *database.Handle {
+	t.Helper()
+	ctx := context.Background()
+	handle, err := database.Open(ctx, database.Config{
+		Driver:            driver,
+		DSN:               dsn,
+		MaxOpenConns:      10,
+		MaxIdleConns:      10,
+		ConnectAttempts:   1,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("open %s: %v", driver, err)
+	}
+	t.Cleanup(func() { _ = handle.Close() })
+
+	migrator, err := database.NewMigrator(handle.DB(), migrations.FS)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate %s: %v", driver, err)
+	}
+	return handle
+}
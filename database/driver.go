@@ -0,0 +1,107 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ชื่อ driver ที่รองรับ (ค่าของ DB_DRIVER และตรงกับ gorm.Dialector.Name())
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Drivers driver ทั้งหมดที่รองรับ (แต่ละตัวมีโฟลเดอร์ migration ของตัวเอง)
+var Drivers = []string{DriverPostgres, DriverMySQL, DriverSQLite}
+
+// defaultSQLiteDSN ไฟล์ฐานข้อมูลเมื่อใช้ sqlite แต่ไม่ได้ตั้ง DB_DSN
+const defaultSQLiteDSN = "books.db"
+
+// driverFromEnv อ่าน DB_DRIVER (ค่าเริ่มต้น postgres)
+func driverFromEnv() string {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER")))
+	if driver == "" {
+		return DriverPostgres
+	}
+	return driver
+}
+
+// openDialector เลือก gorm.Dialector ตาม driver พร้อมตั้ง timeout ของคำสั่ง SQL ที่ฝั่ง DB (ถ้า driver รองรับ)
+//   - postgres: statement_timeout
+//   - mysql: max_execution_time (มีผลกับ SELECT เท่านั้น)
+//   - sqlite: ไม่มี — ใช้ deadline ของ ctx อย่างเดียว
+func openDialector(driver, dsn string, statementTimeout time.Duration) (gorm.Dialector, error) {
+	switch driver {
+	case DriverPostgres:
+		return postgres.Open(withStatementTimeout(dsn, statementTimeout)), nil
+	case DriverMySQL:
+		return mysql.Open(withMaxExecutionTime(dsn, statementTimeout)), nil
+	case DriverSQLite:
+		if dsn == "" {
+			dsn = defaultSQLiteDSN
+		}
+		return sqlite.Open(withSQLiteDefaults(dsn)), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q (want one of %s)", driver, strings.Join(Drivers, ", "))
+	}
+}
+
+// withStatementTimeout เติม statement_timeout (มิลลิวินาที) ลง DSN ให้ทุก connection ใน pool
+// pgx ส่งพารามิเตอร์ที่ไม่รู้จักต่อให้ Postgres เป็น runtime parameter ตอนเปิด connection
+// รองรับทั้งแบบ "host=... user=..." และแบบ URL "postgres://..."
+func withStatementTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(dsn, "statement_timeout") {
+		return dsn
+	}
+	milliseconds := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := parsed.Query()
+		query.Set("statement_timeout", milliseconds)
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+	return strings.TrimSpace(dsn) + " statement_timeout=" + milliseconds
+}
+
+// withMaxExecutionTime เติม max_execution_time (มิลลิวินาที) ลง DSN ของ MySQL
+// go-sql-driver/mysql สั่ง SET ตัวแปรที่ไม่รู้จักให้ทุก connection ตอนเปิด
+func withMaxExecutionTime(dsn string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(dsn, "max_execution_time") {
+		return dsn
+	}
+	return appendQueryParam(dsn, "max_execution_time", strconv.FormatInt(timeout.Milliseconds(), 10))
+}
+
+// withSQLiteDefaults รอ lock แทนที่จะได้ "database is locked" ทันที และเปิด foreign key
+// _txlock=immediate ให้ transaction จอง write lock ตั้งแต่ BEGIN กัน deadlock ตอนอัปเกรดจาก read เป็น write
+func withSQLiteDefaults(dsn string) string {
+	defaults := [][2]string{{"_busy_timeout", "5000"}, {"_foreign_keys", "1"}, {"_txlock", "immediate"}}
+	for _, param := range defaults {
+		if !strings.Contains(dsn, param[0]+"=") {
+			dsn = appendQueryParam(dsn, param[0], param[1])
+		}
+	}
+	return dsn
+}
+
+func appendQueryParam(dsn, key, value string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + key + "=" + value
+}
@@ -0,0 +1,405 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// key ของ lock ระดับฐานข้อมูล — replica ที่เริ่มพร้อมกันจะรอกันที่นี่ ไม่รัน migration ซ้อนกัน
+const (
+	migrationLockKey  int64 = 7_106_230_001      // pg_advisory_lock (postgres)
+	migrationLockName       = "books_migrations" // GET_LOCK (mysql)
+	mysqlLockTimeout        = 600                // วินาที
+)
+
+// migrationFilePattern <version>_<name>.(up|down).sql เช่น 0001_create_books.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration หนึ่งเวอร์ชัน มีทั้ง SQL ขาขึ้นและขาลง
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus สถานะของแต่ละเวอร์ชัน (AppliedAt = nil คือยังไม่ได้รัน)
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration แถวในตาราง schema_migrations
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrator รัน migration จาก fs.FS (ปกติคือ migrations.FS ที่ฝังในไบนารี)
+type Migrator struct {
+	db         *gorm.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewMigrator อ่านและตรวจไฟล์ migration จากโฟลเดอร์ของ driver ที่ db ใช้อยู่ (เช่น source/postgres)
+func NewMigrator(db *gorm.DB, source fs.FS) (*Migrator, error) {
+	driver := db.Dialector.Name()
+	driverSource, err := fs.Sub(source, driver)
+	if err != nil {
+		return nil, fmt.Errorf("migrations for driver %q: %w", driver, err)
+	}
+	migrations, err := LoadMigrations(driverSource)
+	if err != nil {
+		return nil, fmt.Errorf("migrations for driver %q: %w", driver, err)
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+// LoadMigrations อ่านไฟล์ .sql เรียงตามเวอร์ชัน — ทุกเวอร์ชันต้องมีทั้ง up และ down และเวอร์ชันห้ามซ้ำ
+func LoadMigrations(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q (want <version>_<name>.up.sql|.down.sql)", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by both %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have non-empty up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up รันทุกเวอร์ชันที่ยังไม่ได้รัน (แต่ละเวอร์ชันอยู่ใน transaction ของตัวเอง) คืนรายการที่รันไป
+// หมายเหตุ: MySQL commit DDL ทันที ถ้า migration ล้มกลางไฟล์ต้องเก็บกวาดเอง
+func (migrator *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := migrator.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrator.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if preflight, ok := migrationPreflights[migration.Version]; ok {
+					if err := preflight(tx); err != nil {
+						return err
+					}
+				}
+				if err := execStatements(tx, migration.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down ย้อนเวอร์ชันล่าสุดที่รันไปแล้วทีละเวอร์ชัน จำนวน steps เวอร์ชัน คืนรายการที่ย้อน
+func (migrator *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := migrator.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for index := len(migrator.migrations) - 1; index >= 0 && len(reverted) < steps; index-- {
+			migration := migrator.migrations[index]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := execStatements(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status คืนทุกเวอร์ชันพร้อมเวลาที่รัน (ไม่ล็อก อ่านอย่างเดียว — readiness probe เรียกผ่าน Pending)
+// ยังไม่มีตาราง schema_migrations = ยังไม่เคยรันเลย ทุกเวอร์ชันจึงค้าง (ไม่สร้างตารางให้)
+func (migrator *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db := migrator.db.WithContext(ctx)
+	done := map[int64]schemaMigration{}
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		var err error
+		if done, err = appliedVersions(db); err != nil {
+			return nil, err
+		}
+	}
+	statuses := make([]MigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending migration ที่ฝังในไบนารีแต่ยังไม่ได้รันบนฐานข้อมูล (ใช้ตรวจ readiness)
+func (migrator *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// migrationPreflights ตรวจข้อมูลเดิมก่อนรัน migration เวอร์ชันนั้น (ฐานข้อมูลที่เคยใช้ AutoMigrate มาก่อน)
+// ให้ข้อความที่บอกวิธีแก้ แทน error ของ DB ตอนสร้าง index
+var migrationPreflights = map[int64]func(tx *gorm.DB) error{
+	1: checkDuplicateActiveTitles,
+}
+
+// maxReportedDuplicates จำนวนชื่อซ้ำที่แสดงในข้อความ error
+const maxReportedDuplicates = 10
+
+// checkDuplicateActiveTitles ux_books_title_active (0001) สร้างไม่ได้ถ้าตาราง books เดิมมีชื่อซ้ำที่ยังไม่ถูกลบ
+// คืน error ที่บอกชื่อและ id ที่ซ้ำ — แก้ชื่อหรือ soft delete ให้เหลือเล่มเดียวแล้วเริ่มใหม่
+func checkDuplicateActiveTitles(tx *gorm.DB) error {
+	// NewDB: ไม่เอาเงื่อนไขที่ค้างใน conn (เช่น ORDER BY version ของ appliedVersions) มาด้วย
+	tx = tx.Session(&gorm.Session{NewDB: true})
+	if !tx.Migrator().HasTable("books") {
+		return nil
+	}
+	var duplicates []struct {
+		Title string
+		Count int64
+	}
+	err := tx.Table("books").
+		Select("lower(trim(title)) AS title, COUNT(*) AS count").
+		Where("deleted_at IS NULL").
+		Group("lower(trim(title))").
+		Having("COUNT(*) > 1").
+		Order("title").
+		Limit(maxReportedDuplicates + 1).
+		Scan(&duplicates).Error
+	if err != nil {
+		return fmt.Errorf("check duplicate titles: %w", err)
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	details := make([]string, 0, len(duplicates))
+	for index, duplicate := range duplicates {
+		if index == maxReportedDuplicates {
+			details = append(details, "...")
+			break
+		}
+		var ids []int64
+		if err := tx.Table("books").Where("deleted_at IS NULL AND lower(trim(title)) = ?", duplicate.Title).Order("id").Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("check duplicate titles: %w", err)
+		}
+		details = append(details, fmt.Sprintf("%q ids=%v", duplicate.Title, ids))
+	}
+	return fmt.Errorf("books has active rows with duplicate titles (case/space-insensitive), so unique index ux_books_title_active cannot be created: %s — rename or soft delete the extra rows (UPDATE books SET deleted_at = CURRENT_TIMESTAMP WHERE id IN (...)) and restart",
+		strings.Join(details, "; "))
+}
+
+// withLock จับ connection เดียวไว้ตลอด แล้วถือ lock ระดับฐานข้อมูลระหว่างรัน fn
+// (lock ผูกกับ session จึงต้องใช้ connection เดียวกันทั้ง lock/unlock)
+//   - postgres: pg_advisory_lock
+//   - mysql: GET_LOCK
+//   - sqlite: ไม่ต้องล็อก — ไฟล์เดียว เครื่องเดียว และ transaction จอง write lock อยู่แล้ว
+func (migrator *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return migrator.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		unlock, err := migrator.lock(conn)
+		if err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		// ใช้ context ใหม่ เผื่อ ctx เดิมถูกยกเลิกไปแล้ว จะได้ยังปลดล็อกได้
+		defer unlock(conn.WithContext(context.Background()))
+
+		if err := ensureSchemaMigrations(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (migrator *Migrator) lock(conn *gorm.DB) (unlock func(conn *gorm.DB), err error) {
+	switch migrator.driver {
+	case DriverPostgres:
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return nil, err
+		}
+		return func(conn *gorm.DB) { conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey) }, nil
+	case DriverMySQL:
+		var acquired int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, mysqlLockTimeout).Scan(&acquired).Error; err != nil {
+			return nil, err
+		}
+		if acquired != 1 {
+			return nil, fmt.Errorf("timed out after %ds waiting for lock %q", mysqlLockTimeout, migrationLockName)
+		}
+		return func(conn *gorm.DB) { conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName) }, nil
+	default:
+		return func(*gorm.DB) {}, nil
+	}
+}
+
+// ensureSchemaMigrations สร้างตาราง schema_migrations ด้วย DDL ของ driver นั้นๆ (ผ่าน GORM Migrator)
+func ensureSchemaMigrations(db *gorm.DB) error {
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		return nil
+	}
+	if err := db.Migrator().CreateTable(&schemaMigration{}); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// execStatements รันทีละคำสั่ง (แยกด้วย ";" ท้ายบรรทัด) — MySQL ไม่รับหลายคำสั่งใน Exec เดียว
+// บรรทัดที่ขึ้นต้นด้วย "--" เป็น comment จึงข้ามไป (ไฟล์ที่มีแต่ comment ไม่รันอะไรเลย)
+func execStatements(tx *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+func appliedVersions(db *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	done := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// CreateMigrationFiles สร้างไฟล์ up/down เปล่าในโฟลเดอร์ของทุก driver ใต้ root
+// ด้วยเวอร์ชันถัดจากเวอร์ชันสูงสุดที่มีอยู่ (เวอร์ชันเดียวกันทุก driver) คืน path ที่สร้าง
+func CreateMigrationFiles(root, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	var next int64 = 1
+	for _, driver := range Drivers {
+		existing, err := LoadMigrations(os.DirFS(filepath.Join(root, driver)))
+		if err != nil {
+			return nil, fmt.Errorf("migrations for driver %q: %w", driver, err)
+		}
+		if len(existing) > 0 && existing[len(existing)-1].Version >= next {
+			next = existing[len(existing)-1].Version + 1
+		}
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	var created []string
+	for _, driver := range Drivers {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(root, driver, base+"."+direction+".sql")
+			content := fmt.Sprintf("-- %s (%s, %s)\n", base, driver, direction)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return created, err
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
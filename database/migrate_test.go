@@ -0,0 +1,94 @@
+package database_test
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/migrations"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+)
+
+func openEmptySQLite(t *testing.T) *database.Handle {
+	t.Helper()
+	handle, err := database.Open(context.Background(), database.Config{
+		Driver:            database.DriverSQLite,
+		DSN:               filepath.Join(t.TempDir(), "books.db"),
+		ConnectAttempts:   1,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = handle.Close() })
+	return handle
+}
+
+func TestPendingIsReadOnly(t *testing.T) {
+	handle := openEmptySQLite(t)
+	migrator, err := database.NewMigrator(handle.DB(), migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := fs.Sub(migrations.FS, database.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := database.LoadMigrations(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(all) {
+		t.Errorf("pending = %d, want all %d on a fresh database", len(pending), len(all))
+	}
+	if handle.DB().Migrator().HasTable("schema_migrations") {
+		t.Error("Pending created schema_migrations; a readiness probe must not write")
+	}
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = migrator.Pending(context.Background()); err != nil || len(pending) != 0 {
+		t.Errorf("after Up pending = %v, err = %v, want none", pending, err)
+	}
+}
+
+// ฐานข้อมูลเดิมจาก AutoMigrate ที่มีชื่อซ้ำ: migration ต้องบอกชื่อที่ซ้ำ ไม่ใช่ล้มตอนสร้าง index
+func TestUpReportsDuplicateTitlesOnExistingDatabase(t *testing.T) {
+	handle := openEmptySQLite(t)
+	db := handle.DB()
+	if err := db.AutoMigrate(&models.Book{}); err != nil {
+		t.Fatal(err)
+	}
+	books := []models.Book{{Title: "Dune", Author: "A"}, {Title: " dune ", Author: "B"}, {Title: "Emma", Author: "C"}}
+	if err := db.Create(&books).Error; err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = migrator.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `"dune" ids=[1 2]`) {
+		t.Fatalf("Up err = %v, want duplicate titles reported", err)
+	}
+
+	// soft delete เล่มที่เกินแล้วรันใหม่ได้
+	if err := db.Model(&models.Book{}).Where("id = ?", 2).Update("deleted_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up after cleanup: %v", err)
+	}
+}
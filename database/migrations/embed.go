@@ -0,0 +1,11 @@
+// Package migrations เก็บไฟล์ SQL migration แบบมีเวอร์ชัน ฝังไว้ในไบนารีด้วย embed
+// แยกโฟลเดอร์ตาม driver (postgres/, mysql/, sqlite/) — ทุกโฟลเดอร์ต้องมีเวอร์ชันตรงกัน
+// ชื่อไฟล์: <version>_<name>.up.sql / <version>_<name>.down.sql (สร้างด้วย `go run . migrate create <name>`)
+package migrations
+
+import "embed"
+
+// FS ไฟล์ .sql ทั้งหมด แยกโฟลเดอร์ตามชื่อ driver
+//
+//go:embed postgres/*.sql mysql/*.sql sqlite/*.sql
+var FS embed.FS
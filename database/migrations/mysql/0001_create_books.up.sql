@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS books (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    title       VARCHAR(255)    NOT NULL,
+    author      VARCHAR(255)    NOT NULL,
+    created_at  DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at  DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    deleted_at  DATETIME(3)     NULL,
+    -- MySQL ไม่มี partial index จึงใช้ generated column ที่เป็น NULL เมื่อถูกลบ (unique index ยอมให้ NULL ซ้ำได้)
+    title_active VARCHAR(255) GENERATED ALWAYS AS (CASE WHEN deleted_at IS NULL THEN lower(trim(title)) END) STORED,
+    INDEX idx_books_deleted_at (deleted_at),
+    UNIQUE INDEX ux_books_title_active (title_active)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
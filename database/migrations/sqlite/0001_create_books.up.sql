@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS books (
+    id          INTEGER  PRIMARY KEY AUTOINCREMENT,
+    title       TEXT     NOT NULL,
+    author      TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  DATETIME NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
+
+-- กันชื่อซ้ำ (ไม่สนตัวพิมพ์/ช่องว่างหัวท้าย) เฉพาะหนังสือที่ยังไม่ถูกลบ
+CREATE UNIQUE INDEX IF NOT EXISTS ux_books_title_active
+    ON books (lower(trim(title))) WHERE deleted_at IS NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope           TEXT     NOT NULL,
+    idempotency_key TEXT     NOT NULL,
+    fingerprint     TEXT     NOT NULL,
+    status          INTEGER  NOT NULL DEFAULT 0,
+    content_type    TEXT,
+    response_body   BLOB,
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at      DATETIME NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
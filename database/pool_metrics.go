@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ชื่อ metric เดียวกับ collectors.NewDBStatsCollector ของ client_golang (dashboard สำเร็จรูปใช้ได้เลย)
+// label db_name = primary | replica-1 | replica-2 ...
+var (
+	poolMaxOpenDesc      = poolDesc("go_sql_max_open_connections", "Maximum number of open connections to the database.")
+	poolOpenDesc         = poolDesc("go_sql_open_connections", "The number of established connections both in use and idle.")
+	poolInUseDesc        = poolDesc("go_sql_in_use_connections", "The number of connections currently in use.")
+	poolIdleDesc         = poolDesc("go_sql_idle_connections", "The number of idle connections.")
+	poolWaitCountDesc    = poolDesc("go_sql_wait_count_total", "The total number of connections waited for.")
+	poolWaitDurationDesc = poolDesc("go_sql_wait_duration_seconds_total", "The total time blocked waiting for a new connection.")
+	poolMaxIdleDesc      = poolDesc("go_sql_max_idle_closed_total", "The total number of connections closed due to SetMaxIdleConns.")
+	poolMaxIdleTimeDesc  = poolDesc("go_sql_max_idle_time_closed_total", "The total number of connections closed due to SetConnMaxIdleTime.")
+	poolMaxLifetimeDesc  = poolDesc("go_sql_max_lifetime_closed_total", "The total number of connections closed due to SetConnMaxLifetime.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(name, help, []string{"db_name"}, nil)
+}
+
+// poolCollector อ่าน sql.DBStats ของ primary และ replica ที่เปิดแล้วตอนถูก scrape
+type poolCollector struct {
+	handle *Handle
+}
+
+// PoolCollector collector ของ connection pool (primary + replica) สำหรับ metrics.MustRegister
+func (handle *Handle) PoolCollector() prometheus.Collector {
+	return poolCollector{handle: handle}
+}
+
+func (collector poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		poolMaxOpenDesc, poolOpenDesc, poolInUseDesc, poolIdleDesc,
+		poolWaitCountDesc, poolWaitDurationDesc, poolMaxIdleDesc, poolMaxIdleTimeDesc, poolMaxLifetimeDesc,
+	} {
+		descs <- desc
+	}
+}
+
+func (collector poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	collectPool(metrics, "primary", collector.handle.Stats())
+	for name, stats := range collector.handle.ReplicaStats() {
+		collectPool(metrics, name, stats)
+	}
+}
+
+func collectPool(metrics chan<- prometheus.Metric, name string, stats sql.DBStats) {
+	gauge := func(desc *prometheus.Desc, value float64) {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, name)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, name)
+	}
+	gauge(poolMaxOpenDesc, float64(stats.MaxOpenConnections))
+	gauge(poolOpenDesc, float64(stats.OpenConnections))
+	gauge(poolInUseDesc, float64(stats.InUse))
+	gauge(poolIdleDesc, float64(stats.Idle))
+	counter(poolWaitCountDesc, float64(stats.WaitCount))
+	counter(poolWaitDurationDesc, stats.WaitDuration.Seconds())
+	counter(poolMaxIdleDesc, float64(stats.MaxIdleClosed))
+	counter(poolMaxIdleTimeDesc, float64(stats.MaxIdleTimeClosed))
+	counter(poolMaxLifetimeDesc, float64(stats.MaxLifetimeClosed))
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+)
+
+// QueryComment ปลั๊กอิน GORM ที่เติม /* request_id=... */ หน้า SQL ทุกคำสั่งที่มี request ID ใน ctx
+// ทำให้จับคู่ slow query / pg_stat_activity กับ access log ได้ (repository ต้องเรียกผ่าน db.WithContext(ctx))
+type QueryComment struct{}
+
+// Name ชื่อปลั๊กอิน (gorm.Plugin)
+func (QueryComment) Name() string { return "request_id_comment" }
+
+// Initialize ผูก callback ก่อนสร้าง SQL ของแต่ละประเภทคำสั่ง (gorm.Plugin)
+func (QueryComment) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []struct {
+		err    error
+		clause string
+	}{
+		{callbacks.Create().Before("gorm:create").Register("request_id:comment", commentCallback("INSERT")), "INSERT"},
+		{callbacks.Query().Before("gorm:query").Register("request_id:comment", commentCallback("SELECT")), "SELECT"},
+		{callbacks.Update().Before("gorm:update").Register("request_id:comment", commentCallback("UPDATE")), "UPDATE"},
+		{callbacks.Delete().Before("gorm:delete").Register("request_id:comment", commentCallback("DELETE")), "DELETE"},
+		{callbacks.Row().Before("gorm:row").Register("request_id:comment", commentCallback("SELECT")), "SELECT"},
+	}
+	for _, registration := range registrations {
+		if registration.err != nil {
+			return registration.err
+		}
+	}
+	return nil
+}
+
+// sqlComment expression ที่ render เป็น /* ... */ (ใส่เป็น BeforeExpression ของ clause หลัก)
+type sqlComment string
+
+func (comment sqlComment) Build(builder clause.Builder) {
+	builder.WriteString("/* " + string(comment) + " */")
+}
+
+func commentCallback(clauseName string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+		requestID := requestid.FromContext(db.Statement.Context)
+		if requestID == "" {
+			return
+		}
+		// กันปิด comment ก่อนเวลา (ID ผ่าน requestid.Valid มาแล้ว แต่กันไว้อีกชั้น)
+		requestID = strings.ReplaceAll(requestID, "*/", "")
+
+		mainClause := db.Statement.Clauses[clauseName]
+		mainClause.BeforeExpression = sqlComment("request_id=" + requestID)
+		db.Statement.Clauses[clauseName] = mainClause
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/metrics"
+)
+
+// queryStartKey key ของเวลาเริ่มคำสั่งใน instance ของ gorm.Statement
+const queryStartKey = "metrics:query_start"
+
+// QueryMetrics ปลั๊กอิน GORM ที่จับเวลาทุกคำสั่ง SQL ลง histogram db_query_duration_seconds
+// (callback แรกจดเวลาเริ่ม callback สุดท้ายบันทึกผล — ครอบทั้ง callback อื่นของ GORM ด้วย)
+type QueryMetrics struct{}
+
+// Name ชื่อปลั๊กอิน (gorm.Plugin)
+func (QueryMetrics) Name() string { return "query_metrics" }
+
+// Initialize ผูก callback ต้น-ท้ายของแต่ละประเภทคำสั่ง (gorm.Plugin)
+func (QueryMetrics) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("*").Register("metrics:start", startQuery),
+		callbacks.Create().After("*").Register("metrics:observe", observeQuery("create")),
+		callbacks.Query().Before("*").Register("metrics:start", startQuery),
+		callbacks.Query().After("*").Register("metrics:observe", observeQuery("query")),
+		callbacks.Update().Before("*").Register("metrics:start", startQuery),
+		callbacks.Update().After("*").Register("metrics:observe", observeQuery("update")),
+		callbacks.Delete().Before("*").Register("metrics:start", startQuery),
+		callbacks.Delete().After("*").Register("metrics:observe", observeQuery("delete")),
+		callbacks.Row().Before("*").Register("metrics:start", startQuery),
+		callbacks.Row().After("*").Register("metrics:observe", observeQuery("row")),
+		callbacks.Raw().Before("*").Register("metrics:start", startQuery),
+		callbacks.Raw().After("*").Register("metrics:observe", observeQuery("raw")),
+	}
+	return errors.Join(registrations...)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		// ไม่พบแถวไม่ใช่ความผิดพลาดของฐานข้อมูล
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		metrics.ObserveQuery(operation, table, time.Since(start), failed)
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/tracing"
+)
+
+// querySpanKey key ของ span ใน instance ของ gorm.Statement
+const querySpanKey = "tracing:query_span"
+
+// QueryTracing ปลั๊กอิน GORM ที่สร้าง client span ต่อคำสั่ง SQL เป็นลูกของ span ใน ctx (repository ต้องเรียกผ่าน db.WithContext(ctx))
+// บันทึก SQL แบบ placeholder (ไม่มีค่าของ parameter) ชื่อตาราง และจำนวนแถวที่กระทบ
+type QueryTracing struct{}
+
+// Name ชื่อปลั๊กอิน (gorm.Plugin)
+func (QueryTracing) Name() string { return "query_tracing" }
+
+// Initialize ผูก callback ต้น-ท้ายของแต่ละประเภทคำสั่ง (gorm.Plugin)
+func (QueryTracing) Initialize(db *gorm.DB) error {
+	system := dbSystem(db.Dialector.Name())
+	callbacks := db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("*").Register("tracing:start", startQuerySpan("create", system)),
+		callbacks.Create().After("*").Register("tracing:end", endQuerySpan),
+		callbacks.Query().Before("*").Register("tracing:start", startQuerySpan("query", system)),
+		callbacks.Query().After("*").Register("tracing:end", endQuerySpan),
+		callbacks.Update().Before("*").Register("tracing:start", startQuerySpan("update", system)),
+		callbacks.Update().After("*").Register("tracing:end", endQuerySpan),
+		callbacks.Delete().Before("*").Register("tracing:start", startQuerySpan("delete", system)),
+		callbacks.Delete().After("*").Register("tracing:end", endQuerySpan),
+		callbacks.Row().Before("*").Register("tracing:start", startQuerySpan("row", system)),
+		callbacks.Row().After("*").Register("tracing:end", endQuerySpan),
+		callbacks.Raw().Before("*").Register("tracing:start", startQuerySpan("raw", system)),
+		callbacks.Raw().After("*").Register("tracing:end", endQuerySpan),
+	}
+	return errors.Join(registrations...)
+}
+
+// dbSystem ชื่อ driver → ค่า db.system ตาม semconv
+func dbSystem(driver string) attribute.KeyValue {
+	switch driver {
+	case DriverPostgres:
+		return semconv.DBSystemPostgreSQL
+	case DriverMySQL:
+		return semconv.DBSystemMySQL
+	case DriverSQLite:
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemKey.String(driver)
+	}
+}
+
+func startQuerySpan(operation string, system attribute.KeyValue) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(system, semconv.DBOperationName(operation)))
+		db.Statement.Context = ctx
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+func endQuerySpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()), attribute.Int64("db.rows_affected", db.RowsAffected))
+	// ไม่พบแถวไม่ใช่ความผิดพลาดของฐานข้อมูล
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
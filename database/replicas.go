@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// replica read replica หนึ่งตัว สถานะ healthy มาจาก ping ตอนเปิด ping เป็นระยะ และการอ่านที่ล้มระดับ connection (ReplicaFailed)
+// db เป็น nil จนกว่าจะเปิดได้สำเร็จ (บาง driver เช่น mysql คุยกับ server ตั้งแต่ตอน Open)
+type replica struct {
+	name       string
+	dsn        string
+	db         atomic.Pointer[gorm.DB]
+	healthy    atomic.Bool
+	rechecking atomic.Bool // มี goroutine ping ใหม่หลัง ReplicaFailed ค้างอยู่แล้ว
+}
+
+// openReplicas เตรียม replica ตาม config.ReplicaDSNs ด้วย driver/pool เดียวกับ primary
+// replica ที่ยังต่อไม่ได้ไม่ทำให้โปรแกรมล้ม — ถูกทำเป็น unhealthy แล้ว ping ครั้งถัดไปจะลองใหม่
+func openReplicas(ctx context.Context, config Config) []*replica {
+	replicas := make([]*replica, 0, len(config.ReplicaDSNs))
+	for index, dsn := range config.ReplicaDSNs {
+		member := &replica{name: fmt.Sprintf("replica-%d", index+1), dsn: dsn}
+		if err := member.check(ctx, config, 5*time.Second); err != nil {
+			logger.Warnf("database", "%s unavailable, reads go to primary: %v", member.name, err)
+		}
+		replicas = append(replicas, member)
+	}
+	return replicas
+}
+
+// check เปิด replica (ถ้ายังไม่ได้เปิด) แล้ว ping เก็บผลไว้ใน healthy คืน error ของ ping
+func (member *replica) check(ctx context.Context, config Config, timeout time.Duration) error {
+	db := member.db.Load()
+	if db == nil {
+		opened, err := openReplica(config, member.dsn)
+		if err != nil {
+			member.healthy.Store(false)
+			return err
+		}
+		member.db.Store(opened)
+		db = opened
+	}
+	sqlDB, err := db.DB()
+	if err == nil {
+		pingContext, cancel := context.WithTimeout(ctx, timeout)
+		err = sqlDB.PingContext(pingContext)
+		cancel()
+	}
+	member.healthy.Store(err == nil)
+	return err
+}
+
+func openReplica(config Config, dsn string) (*gorm.DB, error) {
+	dialector, err := openDialector(config.Driver, dsn, config.StatementTimeout)
+	if err != nil {
+		return nil, err
+	}
+	// ไม่ ping ตอน Open — ping แยกใน check
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, DisableAutomaticPing: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := usePlugins(db); err != nil {
+		closeDB(db)
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		closeDB(db)
+		return nil, err
+	}
+	configurePool(sqlDB, config)
+	return db, nil
+}
+
+// Reader คืน *gorm.DB สำหรับอ่าน: วน round-robin เฉพาะ replica ที่ healthy
+// ไม่มี replica หรือ replica ล่มหมด → primary (failover)
+func (handle *Handle) Reader() *gorm.DB {
+	count := len(handle.replicas)
+	if count == 0 {
+		return handle.db
+	}
+	start := handle.nextReplica.Add(1)
+	for offset := 0; offset < count; offset++ {
+		member := handle.replicas[(int(start)+offset)%count]
+		if db := member.db.Load(); db != nil && member.healthy.Load() {
+			return db
+		}
+	}
+	return handle.db
+}
+
+// ReplicaFailed รับ error ของการอ่านผ่าน db ที่ Reader คืนมา ถ้า db เป็น replica และ error เป็นระดับ connection
+// (ดู IsConnectionError) จะทำ replica นั้นเป็น unhealthy ทันที — ไม่ต้องรอ ping รอบถัดไป — แล้ว ping ใหม่เบื้องหลัง
+// คืน true เมื่อผู้เรียกควรอ่านซ้ำที่ primary
+func (handle *Handle) ReplicaFailed(db *gorm.DB, err error) bool {
+	if !IsConnectionError(err) {
+		return false
+	}
+	for _, member := range handle.replicas {
+		if db == nil || member.db.Load() != db {
+			continue
+		}
+		if member.healthy.Swap(false) {
+			logger.Warnf("database", "%s unhealthy, reads fail over to primary: %v", member.name, err)
+		}
+		handle.recheck(member)
+		return true
+	}
+	return false
+}
+
+// recheck ping replica ใหม่เบื้องหลัง (ทีละหนึ่ง goroutine ต่อ replica) ฟื้นได้เร็วกว่ารอ ping เป็นระยะ
+// ถ้ายังล้มอยู่ ping เป็นระยะ (DB_PING_INTERVAL) จะลองต่อเอง
+func (handle *Handle) recheck(member *replica) {
+	if !member.rechecking.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer member.rechecking.Store(false)
+		if err := member.check(context.Background(), handle.config, 5*time.Second); err == nil {
+			logger.Infof("database", "%s recovered", member.name)
+		}
+	}()
+}
+
+// IsConnectionError error ที่บอกว่าคุยกับ server ไม่ได้ (connection หลุด/ถูกปฏิเสธ/เครือข่ายล้ม)
+// ไม่นับ ctx ถูกยกเลิก/หมดเวลา และ error ของคำสั่ง SQL เอง เพราะไปอ่านที่ primary ก็ได้ผลเหมือนเดิม
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.As(err, &netErr)
+}
+
+// checkReplicas ping replica ทุกตัว (เปิดใหม่ถ้ายังไม่เคยเปิดได้) อัปเดตสถานะ และ log เมื่อสถานะเปลี่ยน
+func (handle *Handle) checkReplicas(ctx context.Context, timeout time.Duration) {
+	for _, member := range handle.replicas {
+		wasHealthy := member.healthy.Load()
+		err := member.check(ctx, handle.config, timeout)
+		switch {
+		case err != nil && wasHealthy:
+			logger.Warnf("database", "%s unhealthy, reads fail over to primary: %v", member.name, err)
+		case err == nil && !wasHealthy:
+			logger.Infof("database", "%s recovered", member.name)
+		}
+	}
+}
+
+// UnhealthyReplicas ชื่อ replica ที่ ping ล่าสุดล้ม (ไม่ ping ใหม่ — ใช้ผลจาก ping เป็นระยะ)
+func (handle *Handle) UnhealthyReplicas() []string {
+	var names []string
+	for _, member := range handle.replicas {
+		if !member.healthy.Load() {
+			names = append(names, member.name)
+		}
+	}
+	return names
+}
+
+// ReplicaStats สถิติ pool ของ replica แต่ละตัว (key = replica-1, replica-2, ...) ตัวที่ยังเปิดไม่ได้จะไม่มีใน map
+func (handle *Handle) ReplicaStats() map[string]sql.DBStats {
+	stats := make(map[string]sql.DBStats, len(handle.replicas))
+	for _, member := range handle.replicas {
+		if sqlDB := member.sqlDB(); sqlDB != nil {
+			stats[member.name] = sqlDB.Stats()
+		}
+	}
+	return stats
+}
+
+func (member *replica) sqlDB() *sql.DB {
+	db := member.db.Load()
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	return sqlDB
+}
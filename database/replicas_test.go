@@ -0,0 +1,86 @@
+package database_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+func init() {
+	// ไม่เขียนไฟล์ log ระหว่างเทสต์
+	logger.SetSinks(nil)
+}
+
+func TestIsConnectionError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad conn", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"network", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"sql error", errors.New("no such table: books"), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := database.IsConnectionError(tc.err); got != tc.want {
+				t.Errorf("IsConnectionError(%v) = %t, want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReplicaFailedFailsOverThenRechecks(t *testing.T) {
+	dir := t.TempDir()
+	handle, err := database.Open(context.Background(), database.Config{
+		Driver:            database.DriverSQLite,
+		DSN:               filepath.Join(dir, "primary.db"),
+		ReplicaDSNs:       []string{filepath.Join(dir, "replica.db")},
+		ConnectAttempts:   1,
+		ConnectBackoff:    time.Millisecond,
+		ConnectMaxBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = handle.Close() })
+
+	replica := handle.Reader()
+	if replica == handle.DB() {
+		t.Fatal("Reader returned primary, want the healthy replica")
+	}
+	// error ที่ไม่ใช่ระดับ connection หรือมาจาก primary ไม่เปลี่ยนอะไร
+	if handle.ReplicaFailed(replica, errors.New("syntax error")) {
+		t.Error("ReplicaFailed(sql error) = true, want false")
+	}
+	if handle.ReplicaFailed(handle.DB(), driver.ErrBadConn) {
+		t.Error("ReplicaFailed(primary) = true, want false")
+	}
+	if unhealthy := handle.UnhealthyReplicas(); len(unhealthy) != 0 {
+		t.Fatalf("UnhealthyReplicas = %v, want none", unhealthy)
+	}
+
+	if !handle.ReplicaFailed(replica, fmt.Errorf("query: %w", driver.ErrBadConn)) {
+		t.Fatal("ReplicaFailed(bad conn) = false, want true (retry on primary)")
+	}
+	// ping ใหม่เบื้องหลังพบว่า replica ยังใช้ได้ → กลับมา healthy โดยไม่ต้องรอ ping เป็นระยะ (ปิดไว้ในเทสต์นี้)
+	deadline := time.Now().Add(5 * time.Second)
+	for slices.Contains(handle.UnhealthyReplicas(), "replica-1") || handle.Reader() != replica {
+		if time.Now().After(deadline) {
+			t.Fatalf("replica not rechecked, unhealthy = %v", handle.UnhealthyReplicas())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
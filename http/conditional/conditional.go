@@ -0,0 +1,72 @@
+// Package conditional ตั้ง header สำหรับ cache ฝั่ง client (Cache-Control, ETag, Last-Modified)
+// และตอบ 304 Not Modified เมื่อ If-None-Match / If-Modified-Since ตรงกับเวอร์ชันปัจจุบัน
+package conditional
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+)
+
+// CacheControl ให้ client เก็บได้แต่ต้องถามก่อนใช้ทุกครั้ง (ได้ 304 เมื่อไม่เปลี่ยน จึงไม่ต้องโหลดรายการซ้ำ)
+const CacheControl = "private, no-cache"
+
+// ListETag weak ETag ของรายการหนังสือ — มาจากจำนวน + updated_at ล่าสุด ไม่ต้องอ่านทั้งรายการ
+// weak เพราะ body ต่างกันได้ตามเวอร์ชัน API/ภาษา แต่ข้อมูลชุดเดียวกัน
+func ListETag(version service.ListVersion) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d:%d", version.Count, version.LastModified.UnixNano()))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// NotModified ตั้ง Cache-Control/ETag/Last-Modified แล้วตรวจ header ของ request
+// ถ้าตรง ตอบ 304 (ไม่มี body) และคืน true — handler ไม่ต้องโหลด/serialize ข้อมูลต่อ
+// ตาม RFC 9110: มี If-None-Match ให้ดูตัวนั้นอย่างเดียว ไม่เช่นนั้นค่อยดู If-Modified-Since
+func NotModified(context *gin.Context, etag string, lastModified time.Time) bool {
+	context.Header("Cache-Control", CacheControl)
+	context.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		context.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := context.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if !etagMatches(ifNoneMatch, etag) {
+			return false
+		}
+	} else if !notModifiedSince(context.GetHeader("If-Modified-Since"), lastModified) {
+		return false
+	}
+
+	context.Status(http.StatusNotModified)
+	context.Abort()
+	return true
+}
+
+// etagMatches เทียบแบบ weak (ไม่สน W/) กับทุกค่าใน If-None-Match หรือ "*"
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedSince เวลาใน header ละเอียดแค่วินาที จึงปัด lastModified ลงก่อนเทียบ
+func notModifiedSince(header string, lastModified time.Time) bool {
+	if header == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
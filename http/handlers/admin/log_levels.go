@@ -0,0 +1,84 @@
+// Package admin handler ของผู้ดูแลระบบ (อยู่ใต้ /admin ป้องกันด้วย middleware.AdminAuth)
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// logLevelsResponse ระดับ log ปัจจุบัน
+type logLevelsResponse struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// updateLogLevelsRequest แก้บางส่วน: level ว่าง = คงเดิม, modules ค่า null = ลบการตั้งทับของโมดูลนั้น
+//
+//	{"level": "info", "modules": {"books": "warn", "api": "debug", "cache": null}}
+type updateLogLevelsRequest struct {
+	Level   string             `json:"level"`
+	Modules map[string]*string `json:"modules"`
+}
+
+// GetLogLevels GET /admin/log-levels
+func GetLogLevels() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.JSON(http.StatusOK, levelsResponse(logger.CurrentLevels()))
+	}
+}
+
+// UpdateLogLevels PUT /admin/log-levels — มีผลทันทีโดยไม่ต้อง restart (ค่าหายเมื่อ restart ให้แก้ LOG_LEVEL* ด้วย)
+// ตรวจทุกค่าก่อน ถ้ามีค่าผิดไม่เปลี่ยนอะไรเลย
+func UpdateLogLevels() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		var request updateLogLevelsRequest
+		if err := context.ShouldBindJSON(&request); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+
+		var global *logger.Level
+		if request.Level != "" {
+			level, err := logger.ParseLevel(request.Level)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			global = &level
+		}
+		modules := make(map[string]*logger.Level, len(request.Modules))
+		for module, name := range request.Modules {
+			if module == "" {
+				context.JSON(http.StatusBadRequest, gin.H{"error": "module name is required"})
+				return
+			}
+			if name == nil {
+				modules[module] = nil
+				continue
+			}
+			level, err := logger.ParseLevel(*name)
+			if err != nil {
+				context.JSON(http.StatusBadRequest, gin.H{"error": module + ": " + err.Error()})
+				return
+			}
+			modules[module] = &level
+		}
+
+		logger.UpdateLevels(global, modules)
+		current := levelsResponse(logger.CurrentLevels())
+		logger.WarnContext(context.Request.Context(), "admin", "log levels changed",
+			logger.String("level", current.Level), logger.Any("modules", current.Modules), logger.String("ip", context.ClientIP()))
+		context.JSON(http.StatusOK, current)
+	}
+}
+
+func levelsResponse(levels logger.Levels) logLevelsResponse {
+	modules := make(map[string]string, len(levels.Modules))
+	for module, level := range levels.Modules {
+		modules[module] = level.String()
+	}
+	return logLevelsResponse{Level: levels.Global.String(), Modules: modules}
+}
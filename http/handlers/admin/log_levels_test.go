@@ -0,0 +1,98 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/handlers/admin"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+const token = "admin-secret"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.SetSinks(nil)
+	os.Exit(m.Run())
+}
+
+// newEngine route ของผู้ดูแลแบบเดียวกับ router.New
+func newEngine() *gin.Engine {
+	engine := gin.New()
+	adminGroup := engine.Group("/admin", middleware.AdminAuth(token))
+	adminGroup.GET("/log-levels", admin.GetLogLevels())
+	adminGroup.PUT("/log-levels", admin.UpdateLogLevels())
+	return engine
+}
+
+func send(engine *gin.Engine, method, authorization, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, "/admin/log-levels", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	engine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestUpdateLogLevels(t *testing.T) {
+	previous := logger.CurrentLevels()
+	t.Cleanup(func() { logger.SetLevels(previous) })
+	logger.SetLevels(logger.Levels{Global: logger.LevelInfo, Modules: map[string]logger.Level{"cache": logger.LevelError}})
+	engine := newEngine()
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		wantStatus    int
+	}{
+		{"no token", "", `{"level":"debug"}`, http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", `{"level":"debug"}`, http.StatusUnauthorized},
+		{"invalid global level", "Bearer " + token, `{"level":"loud"}`, http.StatusBadRequest},
+		// ค่าผิดตัวเดียว ทั้งคำขอไม่มีผล (books=debug ต้องไม่ถูกตั้ง)
+		{"invalid module level", "Bearer " + token, `{"modules":{"books":"debug","api":"loud"}}`, http.StatusBadRequest},
+		{"empty module name", "Bearer " + token, `{"modules":{"":"debug"}}`, http.StatusBadRequest},
+		{"malformed body", "Bearer " + token, `{"level":`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if recorder := send(engine, http.MethodPut, test.authorization, test.body); recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if logger.Enabled("books", logger.LevelDebug) || !logger.Enabled("cache", logger.LevelError) || logger.Enabled("cache", logger.LevelWarn) {
+				t.Fatalf("levels changed by a rejected request: %+v", logger.CurrentLevels())
+			}
+		})
+	}
+
+	t.Run("override takes effect", func(t *testing.T) {
+		recorder := send(engine, http.MethodPut, "Bearer "+token, `{"level":"warn","modules":{"books":"debug","cache":null}}`)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (body %s)", recorder.Code, recorder.Body)
+		}
+		if !logger.Enabled("books", logger.LevelDebug) || logger.Enabled("api", logger.LevelInfo) || !logger.Enabled("cache", logger.LevelWarn) {
+			t.Errorf("levels after update = %+v", logger.CurrentLevels())
+		}
+
+		recorder = send(engine, http.MethodGet, "Bearer "+token, "")
+		var got struct {
+			Level   string            `json:"level"`
+			Modules map[string]string `json:"modules"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Level != "warn" || len(got.Modules) != 1 || got.Modules["books"] != "debug" {
+			t.Errorf("GET /admin/log-levels = %s, want warn with books=debug", recorder.Body)
+		}
+	})
+}
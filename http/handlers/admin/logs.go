@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+const (
+	defaultSearchWindow = time.Hour // from ค่าเริ่มต้น = to - 1 ชั่วโมง
+	defaultSearchLimit  = 1000
+	maxSearchLimit      = 10000
+	searchFlushEvery    = 100 // ส่งผลออกไปทุกกี่บรรทัด
+	tailBuffer          = 256 // บรรทัดที่ค้างได้ต่อ client ก่อนเริ่มข้าม
+	tailHeartbeat       = 15 * time.Second
+)
+
+// SearchLogs GET /admin/logs?from=&to=&level=&module=&q=&limit=
+//
+// ค้นไฟล์ log ที่หมุนแล้ว (รวม .log.gz) ตอบเป็น NDJSON หนึ่งบรรทัดต่อผล ส่งออกไปเรื่อยๆ ระหว่างค้น
+//
+//	from, to  RFC3339 (ค่าเริ่มต้น: to = ตอนนี้, from = to - 1h)
+//	level     ระดับขั้นต่ำ (เช่น warn = warn + error), module ชื่อโมดูลตรงตัว, q ข้อความที่มีในบรรทัด (ไม่สนตัวพิมพ์)
+//	limit     จำนวนผลสูงสุด (1000, ไม่เกิน 10000)
+func SearchLogs() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		filter, err := parseLogFilter(context)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query := logger.SearchQuery{Filter: filter, Limit: defaultSearchLimit}
+		if query.To, err = parseTime(context.Query("to"), time.Now()); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": "to: " + err.Error()})
+			return
+		}
+		if query.From, err = parseTime(context.Query("from"), query.To.Add(-defaultSearchWindow)); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": "from: " + err.Error()})
+			return
+		}
+		if query.From.After(query.To) {
+			context.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+			return
+		}
+		if raw := context.Query("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxSearchLimit {
+				context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit)})
+				return
+			}
+			query.Limit = limit
+		}
+
+		context.Header("Content-Type", "application/x-ndjson")
+		context.Status(http.StatusOK)
+		encoder := json.NewEncoder(context.Writer)
+		encoder.SetEscapeHTML(false)
+		pending := 0
+		matched, err := logger.Search(context.Request.Context(), query, func(line logger.LogLine) error {
+			if err := encoder.Encode(line); err != nil {
+				return err
+			}
+			if pending++; pending >= searchFlushEvery {
+				context.Writer.Flush()
+				pending = 0
+			}
+			return nil
+		})
+		context.Writer.Flush()
+		if err != nil && !errors.Is(err, context.Request.Context().Err()) {
+			// ส่ง header ไปแล้ว เปลี่ยน status ไม่ได้ — บันทึกไว้แทน
+			logger.WarnContext(context.Request.Context(), "admin", "log search failed", logger.Int("matched", matched), logger.Err(err))
+		}
+	}
+}
+
+// TailLogs GET /admin/logs/tail?level=&module=&q= — Server-Sent Events ของบรรทัด log ใหม่แบบสด
+//
+//	event: log      data: {"time":"...","level":"info","module":"books","line":"..."}
+//	event: dropped  data: {"dropped":12}   (client อ่านไม่ทัน บรรทัดเหล่านั้นถูกข้าม)
+//
+// ส่ง comment ": ping" ทุก 15 วินาทีกัน proxy ตัด และจบเองเมื่อ server เริ่มปิด (logger.CloseSubscribers)
+func TailLogs() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		filter, err := parseLogFilter(context)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		subscription := logger.Subscribe(tailBuffer)
+		defer subscription.Close()
+
+		// stream ไม่มีกำหนดจบ: ยกเลิก HTTP_WRITE_TIMEOUT ของ connection นี้
+		_ = http.NewResponseController(context.Writer).SetWriteDeadline(time.Time{})
+		context.Header("Content-Type", "text/event-stream")
+		context.Header("Cache-Control", "no-cache")
+		context.Header("X-Accel-Buffering", "no")
+		context.Status(http.StatusOK)
+		fmt.Fprint(context.Writer, ": connected\n\n")
+		context.Writer.Flush()
+
+		heartbeat := time.NewTicker(tailHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-context.Request.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(context.Writer, ": ping\n\n")
+			case record, ok := <-subscription.Records:
+				if !ok {
+					return
+				}
+				if dropped := subscription.TakeDropped(); dropped > 0 {
+					writeEvent(context, "dropped", gin.H{"dropped": dropped})
+				}
+				line := record.LogLine()
+				if !filter.Matches(line) {
+					continue
+				}
+				writeEvent(context, "log", line)
+			}
+			context.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent เขียนหนึ่ง event ของ SSE (data เป็น JSON บรรทัดเดียว)
+func writeEvent(context *gin.Context, event string, data any) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(context.Writer, "event: %s\ndata: %s\n\n", event, encoded)
+}
+
+// parseLogFilter อ่าน level, module, q (level ว่าง = ทุกระดับ)
+func parseLogFilter(context *gin.Context) (logger.Filter, error) {
+	filter := logger.Filter{Level: logger.LevelTrace, Module: context.Query("module"), Text: context.Query("q")}
+	if raw := context.Query("level"); raw != "" {
+		level, err := logger.ParseLevel(raw)
+		if err != nil {
+			return logger.Filter{}, err
+		}
+		filter.Level = level
+	}
+	return filter, nil
+}
+
+// parseTime RFC3339 (ว่าง = fallback)
+func parseTime(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.New("want RFC3339 such as 2025-08-09T05:00:00+07:00")
+	}
+	return parsed, nil
+}
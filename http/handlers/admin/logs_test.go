@@ -0,0 +1,96 @@
+package admin_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/handlers/admin"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// readEvent อ่าน SSE หนึ่งก้อน (จนถึงบรรทัดว่าง) คืนบรรทัดที่ไม่ว่างของก้อนนั้น
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v (got %q)", err, lines)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+// client ได้เฉพาะบรรทัดที่ผ่าน filter และเมื่อ client ตัดการเชื่อมต่อ handler ต้องจบ (ปล่อย subscription)
+func TestTailLogs(t *testing.T) {
+	handlerDone := make(chan struct{})
+	engine := gin.New()
+	engine.GET("/admin/logs/tail", func(context *gin.Context) {
+		defer close(handlerDone)
+		context.Next()
+	}, admin.TailLogs())
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/logs/tail?level=warn&module=books", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("response = %d %q, want 200 text/event-stream", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(response.Body)
+	// ": connected" มาหลัง Subscribe แล้ว บรรทัดที่เขียนหลังจากนี้จึงไม่หาย
+	if got := readEvent(t, reader); len(got) != 1 || got[0] != ": connected" {
+		t.Fatalf("first event = %q, want the connected comment", got)
+	}
+
+	logger.Info("books", "listed")
+	logger.Warn("cache", "set failed")
+	logger.Warn("books", "title exists", logger.String("title", "Dune"))
+
+	got := readEvent(t, reader)
+	if len(got) != 2 || got[0] != "event: log" || !strings.HasPrefix(got[1], "data: ") {
+		t.Fatalf("event = %q, want one log event", got)
+	}
+	var line logger.LogLine
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(got[1], "data: ")), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Level != "warn" || line.Module != "books" || !strings.Contains(line.Line, "title exists") || !strings.Contains(line.Line, "title=Dune") {
+		t.Errorf("log event = %+v, want the books warning", line)
+	}
+
+	cancel()
+	select {
+	case <-handlerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tail handler still running after the client disconnected")
+	}
+}
+
+func TestTailLogsRejectsInvalidLevel(t *testing.T) {
+	engine := gin.New()
+	engine.GET("/admin/logs/tail", admin.TailLogs())
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/logs/tail?level=loud", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", recorder.Code)
+	}
+}
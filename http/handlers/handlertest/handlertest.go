@@ -0,0 +1,151 @@
+// Package handlertest ของที่เทสต์ handler ของ v1 และ v2 ใช้ร่วมกัน (ใช้จากไฟล์ _test.go เท่านั้น)
+//
+// ทั้งสองเวอร์ชันรันชุด request เดียวกัน (Cases) บนข้อมูลเดียวกัน (SeededRepository)
+// ต่างกันแค่รูปแบบ error ที่ตอบ: v1 เป็น {"error": ...} ส่วน v2 เป็น problem+json
+package handlertest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// Main ใช้เป็น TestMain: gin โหมดเทสต์ และไม่เขียนไฟล์ log ระหว่างเทสต์
+func Main(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.SetSinks(nil)
+	os.Exit(m.Run())
+}
+
+// BrokenRepository repository ที่ DB ล่ม — ทุกเมธอดที่ handler ใช้คืน ErrDatabaseDown
+type BrokenRepository struct{ repository.BookRepository }
+
+var ErrDatabaseDown = apperr.New(apperr.Internal, "database down")
+
+func (BrokenRepository) ListVersion(context.Context) (int64, time.Time, error) {
+	return 0, time.Time{}, ErrDatabaseDown
+}
+func (BrokenRepository) GetByID(context.Context, uint) (*models.Book, error) {
+	return nil, ErrDatabaseDown
+}
+func (BrokenRepository) GetByIDForUpdate(context.Context, uint) (*models.Book, error) {
+	return nil, ErrDatabaseDown
+}
+func (BrokenRepository) ExistsActiveByTitle(context.Context, string) (bool, error) {
+	return false, ErrDatabaseDown
+}
+func (BrokenRepository) SoftDelete(context.Context, uint) error { return ErrDatabaseDown }
+
+// SeededRepository มี id 1 "Dune", id 2 "Emma" และ id 3 "Gone" (ถูกลบแล้ว)
+func SeededRepository(t testing.TB) repository.BookRepository {
+	t.Helper()
+	bookRepository := repository.NewMemoryBookRepository()
+	ctx := context.Background()
+	for _, title := range []string{"Dune", "Emma", "Gone"} {
+		if err := bookRepository.Create(ctx, &models.Book{Title: title, Author: "Author"}); err != nil {
+			t.Fatalf("seed %s: %v", title, err)
+		}
+	}
+	if err := bookRepository.SoftDelete(ctx, 3); err != nil {
+		t.Fatalf("seed delete: %v", err)
+	}
+	return bookRepository
+}
+
+// Routes handler CRUD ของหนังสือหนึ่งเวอร์ชัน
+type Routes struct {
+	GetBooks, GetBook, CreateBook, UpdateBook, DeleteBook func(service.BookService) gin.HandlerFunc
+}
+
+// NewEngine route ของเวอร์ชันนั้นบน service ที่ใช้ repository ที่ให้มา
+func NewEngine(routes Routes, bookRepository repository.BookRepository) *gin.Engine {
+	bookService := service.NewBookService(bookRepository, repository.NewMemoryTxManager())
+	engine := gin.New()
+	engine.Use(i18n.Middleware())
+	engine.GET("/books", routes.GetBooks(bookService))
+	engine.GET("/books/:id", routes.GetBook(bookService))
+	engine.POST("/books", routes.CreateBook(bookService))
+	engine.PUT("/books/:id", routes.UpdateBook(bookService))
+	engine.DELETE("/books/:id", routes.DeleteBook(bookService))
+	return engine
+}
+
+// Case request หนึ่งตัวกับ status ที่ต้องได้ (Broken = ใช้ BrokenRepository ครอบข้อมูลตั้งต้น)
+type Case struct {
+	Name   string
+	Broken bool
+	Method string
+	Path   string
+	Body   string
+	Status int
+}
+
+// Cases กรณี error ของทุก handler — แต่ละเวอร์ชันตรวจเนื้อหา error ของตัวเองตาม Name
+var Cases = []Case{
+	{Name: "get bad id", Method: http.MethodGet, Path: "/books/abc", Status: http.StatusBadRequest},
+	{Name: "get zero id", Method: http.MethodGet, Path: "/books/0", Status: http.StatusBadRequest},
+	{Name: "update bad id", Method: http.MethodPut, Path: "/books/-1", Body: `{"title":"X","author":"Y"}`, Status: http.StatusBadRequest},
+	{Name: "delete bad id", Method: http.MethodDelete, Path: "/books/1.5", Status: http.StatusBadRequest},
+	{Name: "create malformed body", Method: http.MethodPost, Path: "/books", Body: `{"title":`, Status: http.StatusBadRequest},
+	{Name: "create blank title", Method: http.MethodPost, Path: "/books", Body: `{"title":"  ","author":"Y"}`, Status: http.StatusBadRequest},
+
+	{Name: "get missing", Method: http.MethodGet, Path: "/books/99", Status: http.StatusNotFound},
+	{Name: "get soft-deleted", Method: http.MethodGet, Path: "/books/3", Status: http.StatusNotFound},
+	{Name: "update missing", Method: http.MethodPut, Path: "/books/99", Body: `{"title":"X","author":"Y"}`, Status: http.StatusNotFound},
+	{Name: "update soft-deleted", Method: http.MethodPut, Path: "/books/3", Body: `{"title":"X","author":"Y"}`, Status: http.StatusNotFound},
+	{Name: "delete missing", Method: http.MethodDelete, Path: "/books/99", Status: http.StatusNotFound},
+	{Name: "delete soft-deleted", Method: http.MethodDelete, Path: "/books/3", Status: http.StatusNotFound},
+
+	{Name: "create duplicate title", Method: http.MethodPost, Path: "/books", Body: `{"title":"Dune","author":"Y"}`, Status: http.StatusConflict},
+	{Name: "create duplicate title ignores case and spaces", Method: http.MethodPost, Path: "/books", Body: `{"title":"  dUNE ","author":"Y"}`, Status: http.StatusConflict},
+	{Name: "update to another book's title", Method: http.MethodPut, Path: "/books/2", Body: `{"title":"dune","author":"Y"}`, Status: http.StatusConflict},
+	{Name: "create title of soft-deleted book", Method: http.MethodPost, Path: "/books", Body: `{"title":"Gone","author":"Y"}`, Status: http.StatusCreated},
+
+	{Name: "list internal error", Broken: true, Method: http.MethodGet, Path: "/books", Status: http.StatusInternalServerError},
+	{Name: "get internal error", Broken: true, Method: http.MethodGet, Path: "/books/1", Status: http.StatusInternalServerError},
+	{Name: "create internal error", Broken: true, Method: http.MethodPost, Path: "/books", Body: `{"title":"X","author":"Y"}`, Status: http.StatusInternalServerError},
+	{Name: "update internal error", Broken: true, Method: http.MethodPut, Path: "/books/1", Body: `{"title":"X","author":"Y"}`, Status: http.StatusInternalServerError},
+	{Name: "delete internal error", Broken: true, Method: http.MethodDelete, Path: "/books/1", Status: http.StatusInternalServerError},
+}
+
+// Serve ส่ง request ของ test (ภาษาอังกฤษ) เข้า route ของเวอร์ชันนั้นบนข้อมูลตั้งต้นใหม่ แล้วตรวจ status
+func Serve(t *testing.T, routes Routes, test Case) *httptest.ResponseRecorder {
+	t.Helper()
+	bookRepository := SeededRepository(t)
+	if test.Broken {
+		bookRepository = BrokenRepository{bookRepository}
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(test.Method, test.Path, strings.NewReader(test.Body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept-Language", "en")
+	NewEngine(routes, bookRepository).ServeHTTP(recorder, request)
+
+	if recorder.Code != test.Status {
+		t.Fatalf("status = %d, want %d (body %s)", recorder.Code, test.Status, recorder.Body)
+	}
+	return recorder
+}
+
+// ServeThai GET path บนข้อมูลตั้งต้นใหม่โดยขอภาษาไทย
+func ServeThai(t *testing.T, routes Routes, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set("Accept-Language", "th-TH,th;q=0.9")
+	NewEngine(routes, SeededRepository(t)).ServeHTTP(recorder, request)
+	return recorder
+}
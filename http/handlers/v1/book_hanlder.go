@@ -3,24 +3,84 @@ package v1
 import (
 	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/conditional"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/request"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
 	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
 
 	"github.com/gin-gonic/gin"
 )
 
+// writeError แปลง error เป็นสถานะ HTTP + {"error": "..."} ตามรูปแบบของ v1
+// internalMessageKey คือข้อความเมื่อเป็น error ภายใน (เช่น "create failed")
+func writeError(context *gin.Context, err error, internalMessageKey string) {
+	localizer := i18n.FromContext(context)
+	switch {
+	case errors.Is(err, request.ErrInvalidBody):
+		context.JSON(http.StatusBadRequest, gin.H{"error": localizer.BindErrorMessage(err)})
+	case errors.Is(err, request.ErrInvalidID):
+		context.JSON(http.StatusBadRequest, gin.H{"error": localizer.T(i18n.MsgInvalidID)})
+	case errors.Is(err, request.ErrIdempotencyKeyInvalid):
+		context.JSON(http.StatusBadRequest, gin.H{"error": localizer.T(i18n.MsgIdempotencyKeyInvalid)})
+	case errors.Is(err, request.ErrIdempotencyKeyReused):
+		context.JSON(http.StatusUnprocessableEntity, gin.H{"error": localizer.T(i18n.MsgIdempotencyKeyReused)})
+	case errors.Is(err, request.ErrIdempotencyKeyInProgress):
+		context.JSON(http.StatusConflict, gin.H{"error": localizer.T(i18n.MsgIdempotencyKeyInProgress)})
+	case errors.Is(err, service.ErrTitleExists):
+		// 409 สำหรับเคสชื่อซ้ำ
+		context.JSON(http.StatusConflict, gin.H{"error": localizer.T(i18n.MsgBookTitleExists)})
+	case errors.Is(err, service.ErrBadInput):
+		context.JSON(http.StatusBadRequest, gin.H{"error": localizer.T(i18n.MsgBookInputMissing)})
+	case apperr.IsKind(err, apperr.NotFound):
+		context.JSON(http.StatusNotFound, gin.H{"error": localizer.T(i18n.MsgBookNotFound)})
+	case apperr.IsKind(err, apperr.Conflict):
+		context.JSON(http.StatusConflict, gin.H{"error": localizer.T(i18n.MsgConflict)})
+	case apperr.IsKind(err, apperr.Validation):
+		context.JSON(http.StatusBadRequest, gin.H{"error": localizer.T(i18n.MsgValidationFailed)})
+	case apperr.IsKind(err, apperr.Timeout):
+		context.JSON(http.StatusGatewayTimeout, gin.H{"error": localizer.T(i18n.MsgTimeout)})
+	case apperr.IsKind(err, apperr.Canceled):
+		context.JSON(problem.StatusClientClosedRequest, gin.H{"error": localizer.T(i18n.MsgCanceled)})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{"error": localizer.T(internalMessageKey)})
+	}
+}
+
+// RespondError เขียน error แบบ v1 ให้ middleware ที่ใช้ร่วมกันหลายเวอร์ชัน (เช่น Idempotency)
+func RespondError(context *gin.Context, err error) {
+	writeError(context, err, i18n.MsgInternal)
+}
+
 // @Summary ดึงรายการหนังสือทั้งหมด
 // @Tags books
 // @Produce json
+// @Param If-None-Match header string false "ETag จากครั้งก่อน"
+// @Param If-Modified-Since header string false "Last-Modified จากครั้งก่อน"
 // @Success 200 {array} map[string]interface{}
+// @Header 200 {string} ETag "weak ETag ของรายการ"
+// @Header 200 {string} Last-Modified "updated_at ล่าสุด"
+// @Success 304 "รายการไม่เปลี่ยน"
+// @Failure 500 {object} map[string]string
 // @Router /books [get]
 func GetBooks(bookService service.BookService) gin.HandlerFunc {
 	return func(context *gin.Context) {
-		books, err := bookService.GetAll()
+		// conditional GET: เช็ค ETag/Last-Modified ก่อน ถ้าไม่เปลี่ยนตอบ 304 โดยไม่โหลดรายการ
+		version, err := bookService.ListVersion(context.Request.Context())
+		if err != nil {
+			writeError(context, err, i18n.MsgBookListFailed)
+			return
+		}
+		if conditional.NotModified(context, conditional.ListETag(version), version.LastModified) {
+			return
+		}
+
+		books, err := bookService.GetAll(context.Request.Context())
 		if err != nil {
-			context.JSON(http.StatusInternalServerError, gin.H{"error": "cannot get books"})
+			writeError(context, err, i18n.MsgBookListFailed)
 			return
 		}
 		context.JSON(http.StatusOK, books)
@@ -32,14 +92,20 @@ func GetBooks(bookService service.BookService) gin.HandlerFunc {
 // @Produce json
 // @Param id path int true "book id"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /books/{id} [get]
 func GetBook(bookService service.BookService) gin.HandlerFunc {
 	return func(context *gin.Context) {
-		bookID, _ := strconv.Atoi(context.Param("id"))
-		book, err := bookService.GetByID(uint(bookID))
+		bookID, err := request.BookID(context)
 		if err != nil {
-			context.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			writeError(context, err, i18n.MsgInternal)
+			return
+		}
+		book, err := bookService.GetByID(context.Request.Context(), bookID)
+		if err != nil {
+			writeError(context, err, i18n.MsgInternal)
 			return
 		}
 		context.JSON(http.StatusOK, book)
@@ -54,26 +120,19 @@ func GetBook(bookService service.BookService) gin.HandlerFunc {
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /books [post]
 func CreateBook(bookService service.BookService) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		var requestBody dto.CreateBookRequest
-		if err := context.ShouldBindJSON(&requestBody); err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := request.BindJSON(context, &requestBody); err != nil {
+			writeError(context, err, i18n.MsgBookCreateFailed)
 			return
 		}
 
-		createdBook, err := bookService.Create(requestBody)
+		createdBook, err := bookService.Create(context.Request.Context(), requestBody)
 		if err != nil {
-			switch {
-			case errors.Is(err, service.ErrTitleExists):
-				// 409 สำหรับเคสชื่อซ้ำ
-				context.JSON(http.StatusConflict, gin.H{"error": "title already exists"})
-			case errors.Is(err, service.ErrBadInput):
-				context.JSON(http.StatusBadRequest, gin.H{"error": "title and author are required"})
-			default:
-				context.JSON(http.StatusInternalServerError, gin.H{"error": "create failed"})
-			}
+			writeError(context, err, i18n.MsgBookCreateFailed)
 			return
 		}
 		context.JSON(http.StatusCreated, createdBook)
@@ -90,27 +149,25 @@ func CreateBook(bookService service.BookService) gin.HandlerFunc {
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /books/{id} [put]
 func UpdateBook(bookService service.BookService) gin.HandlerFunc {
 	return func(context *gin.Context) {
-		bookID, _ := strconv.Atoi(context.Param("id"))
+		bookID, err := request.BookID(context)
+		if err != nil {
+			writeError(context, err, i18n.MsgBookUpdateFailed)
+			return
+		}
 
 		var requestBody dto.UpdateBookRequest
-		if err := context.ShouldBindJSON(&requestBody); err != nil {
-			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := request.BindJSON(context, &requestBody); err != nil {
+			writeError(context, err, i18n.MsgBookUpdateFailed)
 			return
 		}
 
-		updatedBook, err := bookService.Update(uint(bookID), requestBody)
+		updatedBook, err := bookService.Update(context.Request.Context(), bookID, requestBody)
 		if err != nil {
-			switch {
-			case errors.Is(err, service.ErrTitleExists):
-				context.JSON(http.StatusConflict, gin.H{"error": "title already exists"})
-			case errors.Is(err, service.ErrBadInput):
-				context.JSON(http.StatusBadRequest, gin.H{"error": "title and author are required"})
-			default:
-				context.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-			}
+			writeError(context, err, i18n.MsgBookUpdateFailed)
 			return
 		}
 		context.JSON(http.StatusOK, updatedBook)
@@ -121,13 +178,19 @@ func UpdateBook(bookService service.BookService) gin.HandlerFunc {
 // @Tags books
 // @Param id path int true "book id"
 // @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books/{id} [delete]
 func DeleteBook(bookService service.BookService) gin.HandlerFunc {
 	return func(context *gin.Context) {
-		bookID, _ := strconv.Atoi(context.Param("id"))
-		if err := bookService.Delete(uint(bookID)); err != nil {
-			context.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		bookID, err := request.BookID(context)
+		if err != nil {
+			writeError(context, err, i18n.MsgBookDeleteFailed)
+			return
+		}
+		if err := bookService.Delete(context.Request.Context(), bookID); err != nil {
+			writeError(context, err, i18n.MsgBookDeleteFailed)
 			return
 		}
 		context.Status(http.StatusNoContent)
@@ -0,0 +1,76 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/handlers/handlertest"
+	v1 "github.com/nuba55yo/go-101-BasicCRUD/http/handlers/v1"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
+)
+
+func TestMain(m *testing.M) { handlertest.Main(m) }
+
+var routes = handlertest.Routes{
+	GetBooks:   v1.GetBooks,
+	GetBook:    v1.GetBook,
+	CreateBook: v1.CreateBook,
+	UpdateBook: v1.UpdateBook,
+	DeleteBook: v1.DeleteBook,
+}
+
+func TestBookHandlerErrors(t *testing.T) {
+	english := i18n.ForLocale(i18n.EN)
+	// ข้อความใน {"error": ...} ของแต่ละกรณี (ไม่มี = ตรวจแค่ว่ามี error)
+	wantErrors := map[string]string{
+		"get bad id":          english.T(i18n.MsgInvalidID),
+		"get zero id":         english.T(i18n.MsgInvalidID),
+		"update bad id":       english.T(i18n.MsgInvalidID),
+		"delete bad id":       english.T(i18n.MsgInvalidID),
+		"create blank title":  english.T(i18n.MsgBookInputMissing),
+		"get missing":         english.T(i18n.MsgBookNotFound),
+		"get soft-deleted":    english.T(i18n.MsgBookNotFound),
+		"update missing":      english.T(i18n.MsgBookNotFound),
+		"update soft-deleted": english.T(i18n.MsgBookNotFound),
+		"delete missing":      english.T(i18n.MsgBookNotFound),
+		"delete soft-deleted": english.T(i18n.MsgBookNotFound),
+
+		"create duplicate title":                         english.T(i18n.MsgBookTitleExists),
+		"create duplicate title ignores case and spaces": english.T(i18n.MsgBookTitleExists),
+		"update to another book's title":                 english.T(i18n.MsgBookTitleExists),
+
+		"list internal error":   english.T(i18n.MsgBookListFailed),
+		"get internal error":    english.T(i18n.MsgInternal),
+		"create internal error": english.T(i18n.MsgBookCreateFailed),
+		"update internal error": english.T(i18n.MsgBookUpdateFailed),
+		"delete internal error": english.T(i18n.MsgBookDeleteFailed),
+	}
+	for _, test := range handlertest.Cases {
+		t.Run(test.Name, func(t *testing.T) {
+			recorder := handlertest.Serve(t, routes, test)
+			if recorder.Code < http.StatusBadRequest {
+				return
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Fatalf("want {\"error\": ...}, got %s", recorder.Body)
+			}
+			if want := wantErrors[test.Name]; want != "" && body.Error != want {
+				t.Errorf("error = %q, want %q", body.Error, want)
+			}
+		})
+	}
+}
+
+func TestBookHandlerErrorsAreLocalized(t *testing.T) {
+	recorder := handlertest.ServeThai(t, routes, "/books/99")
+
+	want := i18n.ForLocale(i18n.TH).T(i18n.MsgBookNotFound)
+	if !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("body = %s, want Thai message %q", recorder.Body, want)
+	}
+}
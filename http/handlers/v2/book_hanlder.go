@@ -1,27 +1,45 @@
 package v2
 
 import (
-	"errors"
 	"net/http"
-	"strconv"
 
+	"github.com/gin-gonic/gin"
 	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/conditional"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/request"
 	"github.com/nuba55yo/go-101-BasicCRUD/service"
-	"github.com/gin-gonic/gin"
 )
 
 // tag แยกกับ v1 เพื่อไม่สับสนใน Swagger
+// error ทุกเส้นตอบเป็น application/problem+json (ดู http/problem)
 
 // @Summary List books (v2)
 // @Tags books-v2
 // @Produce json
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Param If-Modified-Since header string false "Last-Modified from a previous response"
 // @Success 200 {object} map[string]interface{}
+// @Header 200 {string} ETag "weak ETag of the list"
+// @Header 200 {string} Last-Modified "latest updated_at"
+// @Success 304 "Not Modified"
+// @Failure 500 {object} problem.Problem
 // @Router /books [get]
 func GetBooks(svc service.BookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		books, err := svc.GetAll()
+		// conditional GET: เช็ค ETag/Last-Modified ก่อน ถ้าไม่เปลี่ยนตอบ 304 โดยไม่โหลดรายการ
+		version, err := svc.ListVersion(c.Request.Context())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot get books"})
+			problem.Respond(c, err)
+			return
+		}
+		if conditional.NotModified(c, conditional.ListETag(version), version.LastModified) {
+			return
+		}
+
+		books, err := svc.GetAll(c.Request.Context())
+		if err != nil {
+			problem.Respond(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"version": "v2", "data": books})
@@ -33,14 +51,20 @@ func GetBooks(svc service.BookService) gin.HandlerFunc {
 // @Produce json
 // @Param id path int true "book id"
 // @Success 200 {object} map[string]interface{}
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /books/{id} [get]
 func GetBook(svc service.BookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		bookID, _ := strconv.Atoi(c.Param("id"))
-		book, err := svc.GetByID(uint(bookID))
+		bookID, err := request.BookID(c)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			problem.Respond(c, err)
+			return
+		}
+		book, err := svc.GetByID(c.Request.Context(), bookID)
+		if err != nil {
+			problem.Respond(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"version": "v2", "data": book})
@@ -53,26 +77,20 @@ func GetBook(svc service.BookService) gin.HandlerFunc {
 // @Produce json
 // @Param body body dto.CreateBookRequest true "payload"
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /books [post]
 func CreateBook(svc service.BookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req dto.CreateBookRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := request.BindJSON(c, &req); err != nil {
+			problem.Respond(c, err)
 			return
 		}
-		created, err := svc.Create(req)
+		created, err := svc.Create(c.Request.Context(), req)
 		if err != nil {
-			switch {
-			case errors.Is(err, service.ErrTitleExists):
-				c.JSON(http.StatusConflict, gin.H{"error": "title already exists"})
-			case errors.Is(err, service.ErrBadInput):
-				c.JSON(http.StatusBadRequest, gin.H{"error": "title and author are required"})
-			default:
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "create failed"})
-			}
+			problem.Respond(c, err)
 			return
 		}
 		c.JSON(http.StatusCreated, gin.H{"version": "v2", "data": created})
@@ -86,28 +104,26 @@ func CreateBook(svc service.BookService) gin.HandlerFunc {
 // @Param id path int true "book id"
 // @Param body body dto.UpdateBookRequest true "payload"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /books/{id} [put]
 func UpdateBook(svc service.BookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		bookID, _ := strconv.Atoi(c.Param("id"))
+		bookID, err := request.BookID(c)
+		if err != nil {
+			problem.Respond(c, err)
+			return
+		}
 		var req dto.UpdateBookRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := request.BindJSON(c, &req); err != nil {
+			problem.Respond(c, err)
 			return
 		}
-		updated, err := svc.Update(uint(bookID), req)
+		updated, err := svc.Update(c.Request.Context(), bookID, req)
 		if err != nil {
-			switch {
-			case errors.Is(err, service.ErrTitleExists):
-				c.JSON(http.StatusConflict, gin.H{"error": "title already exists"})
-			case errors.Is(err, service.ErrBadInput):
-				c.JSON(http.StatusBadRequest, gin.H{"error": "title and author are required"})
-			default:
-				c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-			}
+			problem.Respond(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"version": "v2", "data": updated})
@@ -118,13 +134,19 @@ func UpdateBook(svc service.BookService) gin.HandlerFunc {
 // @Tags books-v2
 // @Param id path int true "book id"
 // @Success 204
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /books/{id} [delete]
 func DeleteBook(svc service.BookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		bookID, _ := strconv.Atoi(c.Param("id"))
-		if err := svc.Delete(uint(bookID)); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		bookID, err := request.BookID(c)
+		if err != nil {
+			problem.Respond(c, err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), bookID); err != nil {
+			problem.Respond(c, err)
 			return
 		}
 		c.Status(http.StatusNoContent)
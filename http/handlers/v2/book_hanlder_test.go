@@ -0,0 +1,75 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/handlers/handlertest"
+	v2 "github.com/nuba55yo/go-101-BasicCRUD/http/handlers/v2"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
+)
+
+func TestMain(m *testing.M) { handlertest.Main(m) }
+
+var routes = handlertest.Routes{
+	GetBooks:   v2.GetBooks,
+	GetBook:    v2.GetBook,
+	CreateBook: v2.CreateBook,
+	UpdateBook: v2.UpdateBook,
+	DeleteBook: v2.DeleteBook,
+}
+
+// problemType "type" ของ problem+json ตาม status (body ที่ parse ไม่ได้มี type ของตัวเอง)
+func problemType(test handlertest.Case) string {
+	switch {
+	case test.Name == "create malformed body":
+		return problem.TypeMalformed
+	case test.Status == http.StatusBadRequest:
+		return problem.TypeValidation
+	case test.Status == http.StatusNotFound:
+		return problem.TypeNotFound
+	case test.Status == http.StatusConflict:
+		return problem.TypeTitleExists
+	default:
+		return problem.TypeInternal
+	}
+}
+
+func TestBookHandlerProblems(t *testing.T) {
+	for _, test := range handlertest.Cases {
+		t.Run(test.Name, func(t *testing.T) {
+			recorder := handlertest.Serve(t, routes, test)
+			if recorder.Code < http.StatusBadRequest {
+				return
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != problem.ContentType {
+				t.Errorf("Content-Type = %q, want %q", contentType, problem.ContentType)
+			}
+			var body problem.Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode problem: %v (body %s)", err, recorder.Body)
+			}
+			if wantType := problemType(test); body.Type != wantType || body.Status != test.Status {
+				t.Errorf("problem type=%q status=%d, want type=%q status=%d", body.Type, body.Status, wantType, test.Status)
+			}
+			if body.Title == "" || body.Instance != test.Path {
+				t.Errorf("problem title=%q instance=%q, want a title and instance %q", body.Title, body.Instance, test.Path)
+			}
+		})
+	}
+}
+
+func TestBookHandlerProblemsAreLocalized(t *testing.T) {
+	recorder := handlertest.ServeThai(t, routes, "/books/99")
+
+	var body problem.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	thai := i18n.ForLocale(i18n.TH)
+	if body.Title != thai.T(i18n.TitleNotFound) || body.Detail != thai.T(i18n.MsgBookNotFound) {
+		t.Errorf("problem = %+v, want Thai title and detail", body)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// AdminAuth ป้องกัน route ของผู้ดูแลด้วย header "Authorization: Bearer <token>"
+// เทียบ token แบบ constant-time กันเดาทีละตัวอักษรจากเวลาที่ตอบ
+// token ว่าง = ปฏิเสธทุก request (ไม่เช่นนั้น "Authorization: Bearer " เปล่าๆ จะผ่าน)
+func AdminAuth(token string) gin.HandlerFunc {
+	expected := []byte(token)
+	return func(context *gin.Context) {
+		provided, ok := strings.CutPrefix(context.GetHeader("Authorization"), "Bearer ")
+		if !ok || len(expected) == 0 || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			logger.WarnContext(context.Request.Context(), "admin", "unauthorized",
+				logger.String("method", context.Request.Method), logger.String("path", context.Request.URL.Path), logger.String("ip", context.ClientIP()))
+			context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		context.Next()
+	}
+}
@@ -0,0 +1,186 @@
+// Package middleware รวม middleware ระดับ HTTP ที่ใช้กับทุกเวอร์ชันของ API
+package middleware
+
+import (
+	"bytes"
+	stdcontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/request"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+const (
+	// IdempotencyHeader header ที่ client ส่งมา (ค่าเดียวกัน = request เดียวกัน)
+	IdempotencyHeader = "Idempotency-Key"
+	// ReplayedHeader ตอบกลับเป็น "true" เมื่อ response มาจากผลที่เก็บไว้
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// ErrorResponder เขียน error ลง response ตามรูปแบบของแต่ละเวอร์ชัน (v1: {"error"}, v2: problem+json)
+// middleware จะ Abort ให้เองหลังเรียก
+type ErrorResponder func(context *gin.Context, err error)
+
+// responseRecorder ดัก response body ไว้เก็บลงตาราง idempotency_keys
+type responseRecorder struct {
+	gin.ResponseWriter
+	buffer bytes.Buffer
+}
+
+func (writer *responseRecorder) Write(data []byte) (int, error) {
+	writer.buffer.Write(data)
+	return writer.ResponseWriter.Write(data)
+}
+
+// Idempotency ทำให้ POST ที่มี header Idempotency-Key ทำงานครั้งเดียว
+//   - ครั้งแรก: จองคีย์ → ทำงานจริง → เก็บสถานะ + body (ถ้าเป็น 5xx/499 หรือถูกยกเลิก/หมดเวลา จะปล่อยคีย์ให้ retry ได้)
+//   - ครั้งต่อไปที่ body เหมือนเดิม: ตอบผลเดิมซ้ำ พร้อม Idempotent-Replayed: true
+//   - คีย์เดิมแต่ body ต่าง: 422 / request แรกยังไม่เสร็จ: 409
+//
+// method อื่นหรือ request ที่ไม่มี header ผ่านไปตามปกติ
+func Idempotency(idempotencyRepository repository.IdempotencyRepository, ttl time.Duration, respondError ErrorResponder) gin.HandlerFunc {
+	abortWithError := func(context *gin.Context, err error) {
+		respondError(context, err)
+		context.Abort()
+	}
+
+	return func(context *gin.Context) {
+		key := context.GetHeader(IdempotencyHeader)
+		if context.Request.Method != http.MethodPost || key == "" {
+			context.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(context, request.ErrIdempotencyKeyInvalid)
+			return
+		}
+
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			abortWithError(context, request.ErrInvalidBody)
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := context.FullPath()
+		if route == "" {
+			route = context.Request.URL.Path
+		}
+		scope := context.Request.Method + " " + route
+		now := time.Now()
+		record := &models.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint(context.Request.Method, context.Request.URL.Path, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		existing, reserved, err := idempotencyRepository.Reserve(context.Request.Context(), record)
+		if err != nil {
+			logger.ErrorfContext(context.Request.Context(), "idempotency", "reserve failed scope=%s: %v", scope, err)
+			abortWithError(context, err)
+			return
+		}
+		if !reserved {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				abortWithError(context, request.ErrIdempotencyKeyReused)
+			case existing.Status == 0:
+				abortWithError(context, request.ErrIdempotencyKeyInProgress)
+			default:
+				context.Header(ReplayedHeader, "true")
+				context.Data(existing.Status, existing.ContentType, existing.ResponseBody)
+				context.Abort()
+			}
+			return
+		}
+
+		// หลัง Next ctx ของ request มักจบไปแล้ว (เกิน route timeout → 504, client ตัดการเชื่อมต่อ → 499)
+		// ถ้าใช้ ctx นั้นปล่อย/บันทึกคีย์ คำสั่ง SQL จะล้มและคีย์ค้าง in-flight ไปจนหมด IDEMPOTENCY_TTL
+		// จึงใช้ ctx ที่ไม่ถูกยกเลิกตาม request แต่ยังพา request ID ไปถึง log และ SQL
+		persistContext := stdcontext.WithoutCancel(context.Request.Context())
+
+		// handler panic → ปล่อยคีย์ก่อนส่งต่อให้ gin.Recovery ไม่ให้คีย์ค้างสถานะ in-flight จนหมดอายุ
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := idempotencyRepository.Release(persistContext, scope, key); err != nil {
+					logger.ErrorfContext(persistContext, "idempotency", "release after panic failed scope=%s: %v", scope, err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: context.Writer}
+		context.Writer = recorder
+		context.Next()
+
+		status := context.Writer.Status()
+		if retryable(context, status) {
+			// error ฝั่งเรา/ถูกยกเลิก/หมดเวลา ไม่ควรจำไว้ ให้ client retry ด้วยคีย์เดิมได้
+			if err := idempotencyRepository.Release(persistContext, scope, key); err != nil {
+				logger.ErrorfContext(persistContext, "idempotency", "release failed scope=%s: %v", scope, err)
+			}
+			return
+		}
+		contentType := context.Writer.Header().Get("Content-Type")
+		if err := idempotencyRepository.Complete(persistContext, scope, key, status, contentType, recorder.buffer.Bytes()); err != nil {
+			logger.ErrorfContext(persistContext, "idempotency", "complete failed scope=%s: %v", scope, err)
+		}
+	}
+}
+
+// retryable ผลที่ไม่ควรเก็บไว้ตอบซ้ำ: 5xx (รวม 504), 499 (client ตัดการเชื่อมต่อ)
+// หรือ handler แนบ error ชนิด Canceled/Timeout ไว้ใน context.Errors แม้จะตอบด้วยสถานะอื่น
+func retryable(context *gin.Context, status int) bool {
+	if status >= http.StatusInternalServerError || status == problem.StatusClientClosedRequest {
+		return true
+	}
+	for _, ginErr := range context.Errors {
+		if apperr.IsKind(ginErr.Err, apperr.Canceled) || apperr.IsKind(ginErr.Err, apperr.Timeout) {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint sha256 ของ method + path + body ใช้เทียบว่า retry เป็น request เดียวกันจริงไหม
+func fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// RunIdempotencyCleanup ลบคีย์ที่หมดอายุทุก interval จนกว่า ctx จะถูกยกเลิก (เรียกด้วย go ...)
+func RunIdempotencyCleanup(ctx stdcontext.Context, idempotencyRepository repository.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := idempotencyRepository.DeleteExpired(ctx, now)
+			if err != nil {
+				logger.Errorf("idempotency", "cleanup failed: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				logger.Infof("idempotency", "cleanup deleted=%d", deleted)
+			}
+		}
+	}
+}
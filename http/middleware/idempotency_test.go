@@ -0,0 +1,160 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	logger.SetSinks(nil)
+}
+
+// ctxCheckingRepository ทำตัวเหมือน GORM: Release/Complete ล้มถ้า ctx ถูกยกเลิกแล้ว
+type ctxCheckingRepository struct {
+	repository.IdempotencyRepository
+}
+
+func (wrapped ctxCheckingRepository) Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return wrapped.IdempotencyRepository.Complete(ctx, scope, key, status, contentType, body)
+}
+
+func (wrapped ctxCheckingRepository) Release(ctx context.Context, scope, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return wrapped.IdempotencyRepository.Release(ctx, scope, key)
+}
+
+func TestIdempotencyReleasesKeyAfterRouteTimeout(t *testing.T) {
+	idempotencyRepository := ctxCheckingRepository{repository.NewMemoryIdempotencyRepository()}
+	calls := 0
+	engine := gin.New()
+	engine.POST("/books",
+		middleware.Idempotency(idempotencyRepository, time.Hour, problem.Respond),
+		middleware.Timeout(time.Millisecond),
+		func(c *gin.Context) {
+			calls++
+			if calls == 1 {
+				// ครั้งแรกเกินเวลา → 504 (ctx ของ request หมดอายุแล้ว)
+				<-c.Request.Context().Done()
+				c.Status(http.StatusGatewayTimeout)
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{"id": 1})
+		},
+	)
+
+	send := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{"title":"Dune"}`))
+		request.Header.Set(middleware.IdempotencyHeader, "key-1")
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+	if recorder := send(); recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("first status = %d, want 504", recorder.Code)
+	}
+	// คีย์ต้องถูกปล่อย retry จึงทำงานจริง (ไม่ใช่ 409 in progress)
+	if recorder := send(); recorder.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want 201 (body %s)", recorder.Code, recorder.Body)
+	}
+	// และผลของ retry ถูกเก็บไว้ตอบซ้ำ
+	recorder := send()
+	if recorder.Code != http.StatusCreated || recorder.Header().Get(middleware.ReplayedHeader) != "true" {
+		t.Fatalf("replay status = %d replayed=%q, want 201 replayed", recorder.Code, recorder.Header().Get(middleware.ReplayedHeader))
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterClientCancel(t *testing.T) {
+	idempotencyRepository := ctxCheckingRepository{repository.NewMemoryIdempotencyRepository()}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	engine := gin.New()
+	engine.POST("/books", middleware.Idempotency(idempotencyRepository, time.Hour, problem.Respond), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			// client ตัดการเชื่อมต่อระหว่างที่ request แรกกำลังทำงาน (จองคีย์ไปแล้ว)
+			cancel()
+		}
+		if err := c.Request.Context().Err(); err != nil {
+			// service แปลง ctx ที่ถูกยกเลิกเป็น apperr.Canceled → 499
+			problem.Respond(c, apperr.Wrap(apperr.Canceled, "create book", err))
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	first := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{"title":"Dune"}`)).WithContext(ctx)
+	request.Header.Set(middleware.IdempotencyHeader, "key-3")
+	engine.ServeHTTP(first, request)
+	if first.Code != problem.StatusClientClosedRequest {
+		t.Fatalf("first status = %d, want 499", first.Code)
+	}
+
+	// retry ด้วยคีย์เดิมต้องทำงานจริง ไม่ได้ 499 ที่เก็บไว้ตอบซ้ำ
+	retry := httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{"title":"Dune"}`))
+	request.Header.Set(middleware.IdempotencyHeader, "key-3")
+	engine.ServeHTTP(retry, request)
+	if retry.Code != http.StatusCreated || retry.Header().Get(middleware.ReplayedHeader) != "" {
+		t.Fatalf("retry status = %d replayed=%q, want fresh 201", retry.Code, retry.Header().Get(middleware.ReplayedHeader))
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestIdempotencyCompletesAfterClientDisconnect(t *testing.T) {
+	idempotencyRepository := ctxCheckingRepository{repository.NewMemoryIdempotencyRepository()}
+	engine := gin.New()
+	engine.POST("/books", middleware.Idempotency(idempotencyRepository, time.Hour, problem.Respond), func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	// client ตัดการเชื่อมต่อหลัง handler เขียนเสร็จ แต่ก่อน middleware บันทึกผล
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{}`)).WithContext(ctx)
+	request.Header.Set(middleware.IdempotencyHeader, "key-2")
+	cancelOnWrite := &cancelingWriter{ResponseRecorder: recorder, cancel: cancel}
+	engine.ServeHTTP(cancelOnWrite, request)
+
+	retry := httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{}`))
+	request.Header.Set(middleware.IdempotencyHeader, "key-2")
+	engine.ServeHTTP(retry, request)
+	if retry.Code != http.StatusCreated || retry.Header().Get(middleware.ReplayedHeader) != "true" {
+		t.Fatalf("retry status = %d replayed=%q, want stored 201", retry.Code, retry.Header().Get(middleware.ReplayedHeader))
+	}
+}
+
+// cancelingWriter ยกเลิก ctx ของ request ทันทีที่ handler เขียน body
+type cancelingWriter struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (writer *cancelingWriter) Write(data []byte) (int, error) {
+	defer writer.cancel()
+	return writer.ResponseRecorder.Write(data)
+}
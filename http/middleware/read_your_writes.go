@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+)
+
+// ReadYourWrites ให้ทุก request ปักหมุดตัวเองไป primary ได้หลังเขียนสำเร็จ
+// (เช่น PUT แล้วอ่านหนังสือเล่มเดิมใน request เดียวกัน จะไม่ได้ค่าเก่าจาก replica)
+func ReadYourWrites() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Request = context.Request.WithContext(repository.WithReadYourWrites(context.Request.Context()))
+		context.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+)
+
+// RequestID รับ X-Request-ID จาก client (ถ้ารูปแบบถูกต้อง) หรือสร้างใหม่
+// แล้วตอบกลับใน header เดียวกัน และเก็บไว้ใน c.Request.Context() ให้ service/repository/logger ใช้ต่อ
+// ต้องวางก่อน logger.AccessLog เพื่อให้ access log มี request ID ด้วย
+func RequestID() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		requestID := context.GetHeader(requestid.Header)
+		if !requestid.Valid(requestID) {
+			requestID = requestid.Generate()
+		}
+		context.Header(requestid.Header, requestID)
+		context.Request = context.Request.WithContext(requestid.NewContext(context.Request.Context(), requestID))
+		context.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		inbound string
+		echoed  bool // ใช้ ID ที่ client ส่งมา หรือสร้างใหม่
+	}{
+		{"valid inbound id echoed", "gw-01:req_42", true},
+		{"missing id generated", "", false},
+		{"invalid charset replaced", "req 1\nfake=1", false},
+		{"too long replaced", strings.Repeat("a", 129), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var seen string
+			engine := gin.New()
+			engine.GET("/", middleware.RequestID(), func(c *gin.Context) {
+				seen = requestid.FromContext(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.inbound != "" {
+				request.Header.Set(requestid.Header, test.inbound)
+			}
+			engine.ServeHTTP(recorder, request)
+
+			responded := recorder.Header().Get(requestid.Header)
+			if test.echoed && responded != test.inbound {
+				t.Errorf("response id = %q, want inbound %q", responded, test.inbound)
+			}
+			if !test.echoed && (responded == test.inbound || !requestid.Valid(responded)) {
+				t.Errorf("response id = %q, want a freshly generated id", responded)
+			}
+			// handler (และ service/logger ข้างใต้) เห็น ID เดียวกับที่ตอบกลับ
+			if seen != responded {
+				t.Errorf("context id = %q, response header id = %q; want equal", seen, responded)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// Timeout ตั้ง deadline ให้ c.Request.Context() ของ route นี้
+// service/repository ส่ง ctx ต่อให้ GORM (WithContext) คำสั่ง SQL จึงถูกยกเลิกเมื่อเกินเวลา
+// แล้ว handler ตอบ 504 เอง — middleware ไม่แตก goroutine จึงไม่มีปัญหาเขียน response ซ้อนกัน
+// timeout <= 0 คือไม่จำกัด
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		// คืน request เดิมหลัง Next — middleware ชั้นนอก (idempotency, access log, tracing) จะได้ไม่เห็น ctx ที่ถูกยกเลิกแล้ว
+		original := c.Request
+		c.Request = c.Request.WithContext(ctx)
+		defer func() { c.Request = original }()
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.WarnfContext(ctx, "api", "route timeout after %s method=%s route=%s status=%d",
+				timeout, c.Request.Method, c.FullPath(), c.Writer.Status())
+		}
+	}
+}
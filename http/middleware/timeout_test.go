@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+)
+
+func TestTimeoutRestoresRequestForOuterMiddleware(t *testing.T) {
+	var innerErr, outerErr error
+	var innerDeadline bool
+	engine := gin.New()
+	engine.GET("/books",
+		func(c *gin.Context) {
+			c.Next()
+			outerErr = c.Request.Context().Err()
+		},
+		middleware.Timeout(time.Second),
+		func(c *gin.Context) {
+			_, innerDeadline = c.Request.Context().Deadline()
+			innerErr = c.Request.Context().Err()
+			c.Status(http.StatusOK)
+		},
+	)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+
+	if !innerDeadline || innerErr != nil {
+		t.Errorf("handler ctx deadline=%v err=%v, want a live ctx with deadline", innerDeadline, innerErr)
+	}
+	if outerErr != nil {
+		t.Errorf("outer middleware ctx err = %v, want the original (not cancelled) ctx", outerErr)
+	}
+}
+
+func TestTimeoutCancelsHandlerContext(t *testing.T) {
+	var handlerErr error
+	engine := gin.New()
+	engine.GET("/books", middleware.Timeout(time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		handlerErr = c.Request.Context().Err()
+		c.Status(http.StatusGatewayTimeout)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+	if handlerErr != context.DeadlineExceeded {
+		t.Errorf("handler ctx err = %v, want DeadlineExceeded", handlerErr)
+	}
+}
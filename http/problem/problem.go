@@ -0,0 +1,158 @@
+// Package problem แปลง error ของระบบเป็น response แบบ RFC 7807 (application/problem+json)
+// ใช้กับ v2 และเวอร์ชันที่ใหม่กว่า (v1 ยังคงรูปแบบ {"error": "..."} เดิม)
+// title/detail แปลตามภาษาของ request (ดู pkg/i18n) ส่วน type คงที่ไว้ให้ client ใช้ตัดสินใจ
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/request"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// ContentType ของ response ตาม RFC 7807
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest สถานะ (ไม่เป็นมาตรฐาน แต่ใช้กันทั่วไปตาม nginx) เมื่อ client ตัดการเชื่อมต่อ
+// client ไม่ได้รับ response นี้ แต่ access log/metrics จะเห็นแยกจาก 5xx
+const StatusClientClosedRequest = 499
+
+// URI ที่ใช้เป็น "type" ของแต่ละปัญหา (relative reference ตาม RFC 3986)
+const (
+	TypeValidation  = "/problems/validation-error"
+	TypeMalformed   = "/problems/malformed-body"
+	TypeTitleExists = "/problems/title-exists"
+	TypeConflict    = "/problems/conflict"
+
+	TypeIdempotencyKeyInvalid    = "/problems/idempotency-key-invalid"
+	TypeIdempotencyKeyReused     = "/problems/idempotency-key-reused"
+	TypeIdempotencyKeyInProgress = "/problems/idempotency-key-in-progress"
+	TypeNotFound                 = "/problems/not-found"
+	TypeInternal                 = "/problems/internal-error"
+	TypeTimeout                  = "/problems/timeout"
+	TypeCanceled                 = "/problems/client-closed-request"
+)
+
+// FieldError รายละเอียดของฟิลด์ที่ไม่ผ่าน validation
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"title is required"`
+}
+
+// Problem โครงสร้าง body ตาม RFC 7807 + ส่วนขยาย errors
+type Problem struct {
+	Type     string       `json:"type" example:"/problems/validation-error"`
+	Title    string       `json:"title" example:"Validation failed"`
+	Status   int          `json:"status" example:"400"`
+	Detail   string       `json:"detail,omitempty" example:"one or more fields are invalid"`
+	Instance string       `json:"instance,omitempty" example:"/api/v2/books"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// New สร้าง Problem จาก type/status/detail โดยใช้ข้อความสถานะ HTTP เป็น title
+func New(problemType string, status int, detail string) *Problem {
+	return &Problem{Type: problemType, Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// newLocalized สร้าง Problem ที่ title/detail แปลตามภาษาของ request
+func newLocalized(localizer *i18n.Localizer, problemType string, status int, titleKey, detailKey string) *Problem {
+	return &Problem{
+		Type:   problemType,
+		Title:  localizer.T(titleKey),
+		Status: status,
+		Detail: localizer.T(detailKey),
+	}
+}
+
+// FromError แปลง error จาก request/service/repository เป็น Problem
+// error ที่รู้จักเฉพาะ (ชื่อซ้ำ, id ผิด ฯลฯ) ได้ type ของตัวเอง ที่เหลือตัดสินจาก apperr.KindOf
+func FromError(localizer *i18n.Localizer, err error) *Problem {
+	switch {
+	case errors.Is(err, request.ErrInvalidBody):
+		return FromBindError(localizer, err)
+	case errors.Is(err, request.ErrInvalidID):
+		problem := newLocalized(localizer, TypeValidation, http.StatusBadRequest, i18n.TitleValidation, i18n.MsgInvalidID)
+		problem.Errors = []FieldError{{Field: "id", Rule: "numeric", Message: localizer.T(i18n.MsgInvalidID)}}
+		return problem
+	case errors.Is(err, request.ErrIdempotencyKeyInvalid):
+		return newLocalized(localizer, TypeIdempotencyKeyInvalid, http.StatusBadRequest, i18n.TitleValidation, i18n.MsgIdempotencyKeyInvalid)
+	case errors.Is(err, request.ErrIdempotencyKeyReused):
+		return newLocalized(localizer, TypeIdempotencyKeyReused, http.StatusUnprocessableEntity, i18n.TitleUnprocessable, i18n.MsgIdempotencyKeyReused)
+	case errors.Is(err, request.ErrIdempotencyKeyInProgress):
+		return newLocalized(localizer, TypeIdempotencyKeyInProgress, http.StatusConflict, i18n.TitleConflict, i18n.MsgIdempotencyKeyInProgress)
+	case errors.Is(err, service.ErrTitleExists):
+		return newLocalized(localizer, TypeTitleExists, http.StatusConflict, i18n.TitleTitleExists, i18n.MsgBookTitleExists)
+	case errors.Is(err, service.ErrBadInput):
+		return newLocalized(localizer, TypeValidation, http.StatusBadRequest, i18n.TitleValidation, i18n.MsgBookInputMissing)
+	}
+
+	switch apperr.KindOf(err) {
+	case apperr.NotFound:
+		return newLocalized(localizer, TypeNotFound, http.StatusNotFound, i18n.TitleNotFound, i18n.MsgBookNotFound)
+	case apperr.Conflict:
+		return newLocalized(localizer, TypeConflict, http.StatusConflict, i18n.TitleConflict, i18n.MsgConflict)
+	case apperr.Validation:
+		return newLocalized(localizer, TypeValidation, http.StatusBadRequest, i18n.TitleValidation, i18n.MsgValidationFailed)
+	case apperr.Timeout:
+		return newLocalized(localizer, TypeTimeout, http.StatusGatewayTimeout, i18n.TitleTimeout, i18n.MsgTimeout)
+	case apperr.Canceled:
+		return newLocalized(localizer, TypeCanceled, StatusClientClosedRequest, i18n.TitleCanceled, i18n.MsgCanceled)
+	default:
+		// ไม่ส่งข้อความภายในออกไป กันข้อมูลระบบรั่ว
+		return newLocalized(localizer, TypeInternal, http.StatusInternalServerError, i18n.TitleInternal, i18n.MsgInternal)
+	}
+}
+
+// FromBindError แปลง error จาก request.BindJSON เป็น Problem
+func FromBindError(localizer *i18n.Localizer, err error) *Problem {
+	if fields, ok := localizer.ValidationErrors(err); ok {
+		problem := newLocalized(localizer, TypeValidation, http.StatusBadRequest, i18n.TitleValidation, i18n.MsgValidationFailed)
+		for _, field := range fields {
+			problem.Errors = append(problem.Errors, FieldError{Field: field.Field, Rule: field.Rule, Message: field.Message})
+		}
+		return problem
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return newLocalized(localizer, TypeMalformed, http.StatusBadRequest, i18n.TitleMalformed, i18n.MsgBodyEmpty)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return newLocalized(localizer, TypeMalformed, http.StatusBadRequest, i18n.TitleMalformed, i18n.MsgBodyMalformed)
+	case errors.As(err, &typeErr):
+		problem := newLocalized(localizer, TypeMalformed, http.StatusBadRequest, i18n.TitleMalformed, i18n.MsgBodyWrongType)
+		problem.Errors = []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: localizer.T(i18n.MsgFieldWrongType, localizer.FieldLabel(typeErr.Field), typeErr.Type.String()),
+		}}
+		return problem
+	default:
+		return newLocalized(localizer, TypeMalformed, http.StatusBadRequest, i18n.TitleMalformed, i18n.MsgBodyInvalid)
+	}
+}
+
+// Write เขียน Problem ลง response และ abort chain ที่เหลือ
+func Write(context *gin.Context, problem *Problem) {
+	if problem.Instance == "" && context.Request != nil {
+		problem.Instance = context.Request.URL.Path
+	}
+	// ใช้ Data แทน JSON เพื่อไม่ให้ gin ทับ Content-Type เป็น application/json
+	body, _ := json.Marshal(problem)
+	context.Abort()
+	context.Data(problem.Status, ContentType, body)
+}
+
+// Respond ย่อจาก Write(context, FromError(i18n.FromContext(context), err))
+func Respond(context *gin.Context, err error) {
+	Write(context, FromError(i18n.FromContext(context), err))
+}
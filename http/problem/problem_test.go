@@ -0,0 +1,39 @@
+package problem_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/request"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+func TestFromErrorConflictTitles(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantType  string
+		wantTitle string
+	}{
+		{name: "title exists", err: service.ErrTitleExists, wantType: problem.TypeTitleExists, wantTitle: i18n.TitleTitleExists},
+		{name: "idempotency key in progress", err: request.ErrIdempotencyKeyInProgress, wantType: problem.TypeIdempotencyKeyInProgress, wantTitle: i18n.TitleConflict},
+		{name: "generic conflict", err: apperr.New(apperr.Conflict, "version mismatch"), wantType: problem.TypeConflict, wantTitle: i18n.TitleConflict},
+	}
+	for _, locale := range []string{i18n.EN, i18n.TH} {
+		localizer := i18n.ForLocale(locale)
+		for _, test := range tests {
+			t.Run(locale+"/"+test.name, func(t *testing.T) {
+				got := problem.FromError(localizer, test.err)
+				if got.Status != http.StatusConflict || got.Type != test.wantType {
+					t.Fatalf("status=%d type=%q, want 409 %q", got.Status, got.Type, test.wantType)
+				}
+				if want := localizer.T(test.wantTitle); got.Title != want || want == test.wantTitle {
+					t.Errorf("title = %q, want %q (key %s must be translated)", got.Title, want, test.wantTitle)
+				}
+			})
+		}
+	}
+}
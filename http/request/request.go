@@ -0,0 +1,41 @@
+// Package request อ่านค่าจาก request (path param, JSON body) ที่เดียว ให้ handler ทุกเวอร์ชันใช้ร่วมกัน
+// error ที่คืนเป็น *apperr.Error เสมอ handler จึงแปลงเป็นสถานะ HTTP ได้ตรงกันทุกเวอร์ชัน
+package request
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// error ของการอ่าน request
+var (
+	ErrInvalidID   = apperr.New(apperr.Validation, "invalid book id")
+	ErrInvalidBody = apperr.New(apperr.Validation, "invalid request body")
+
+	// error ของ header Idempotency-Key (ดู http/middleware)
+	ErrIdempotencyKeyInvalid    = apperr.New(apperr.Validation, "invalid idempotency key")
+	ErrIdempotencyKeyReused     = apperr.New(apperr.Conflict, "idempotency key reused with a different request") // ตอบ 422
+	ErrIdempotencyKeyInProgress = apperr.New(apperr.Conflict, "request with this idempotency key is in progress")
+)
+
+// BookID อ่าน :id จาก path — ต้องเป็นเลขจำนวนเต็มบวก (กัน "/books/abc" กลายเป็น id 0)
+func BookID(context *gin.Context) (uint, error) {
+	bookID, err := strconv.ParseUint(context.Param("id"), 10, 0)
+	if err != nil || bookID == 0 {
+		return 0, ErrInvalidID
+	}
+	return uint(bookID), nil
+}
+
+// BindJSON bind body ลง target แล้ว validate ตาม binding tag
+// error ที่คืนห่อทั้ง ErrInvalidBody และ error เดิม (validator/json) ไว้ให้ errors.Is/As ใช้ต่อ
+func BindJSON(context *gin.Context, target any) error {
+	if err := context.ShouldBindJSON(target); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBody, err)
+	}
+	return nil
+}
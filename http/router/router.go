@@ -1,37 +1,109 @@
 package router
 
 import (
+	"sync"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 
+	"github.com/nuba55yo/go-101-BasicCRUD/http/handlers/admin"
 	v1 "github.com/nuba55yo/go-101-BasicCRUD/http/handlers/v1"
 	v2 "github.com/nuba55yo/go-101-BasicCRUD/http/handlers/v2"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/problem"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/health"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/i18n"
 	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/metrics"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/tracing"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
 	"github.com/nuba55yo/go-101-BasicCRUD/service"
 )
 
-func New(bookService service.BookService) *gin.Engine {
+// Options ค่าที่ router ใช้นอกเหนือจาก service
+type Options struct {
+	IdempotencyRepository repository.IdempotencyRepository
+	IdempotencyTTL        time.Duration // อายุของผลลัพธ์ที่เก็บไว้ตอบซ้ำตาม Idempotency-Key
+
+	// timeout ต่อ route (0 = ไม่จำกัด) — อ่าน (GET) กับเขียน (POST/PUT/DELETE) ตั้งแยกกัน
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Health check ของ /readyz และ /health (nil = ไม่มี check — พร้อมเสมอ)
+	Health *health.Registry
+
+	// AdminToken token ของ route /admin (Authorization: Bearer ...) ว่าง = ปิด route ของผู้ดูแลทั้งหมด
+	AdminToken string
+}
+
+// registerValidatorOnce binding.Validator เป็นของทั้ง process — ผูกคำแปลครั้งเดียวแม้สร้าง router หลายตัว (เช่นในเทสต์)
+var registerValidatorOnce sync.Once
+
+// registerValidator ให้ข้อความ validation แปลตามภาษา (th/en) และใช้ชื่อฟิลด์ตาม json tag
+func registerValidator() {
+	registerValidatorOnce.Do(func() {
+		if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			if err := i18n.RegisterValidator(validate); err != nil {
+				logger.Errorf("api", "register validator translations failed: %v", err)
+			}
+		}
+	})
+}
+
+// New สร้าง Gin engine พร้อม route ทุกเวอร์ชัน
+func New(bookService service.BookService, options Options) *gin.Engine {
 	r := gin.New()
 	_ = r.SetTrustedProxies(nil)
-	r.Use(gin.Logger(), gin.Recovery(), logger.AccessLog())
+	r.Use(gin.Logger(), gin.Recovery(), middleware.RequestID(), tracing.Middleware(), middleware.ReadYourWrites(), metrics.Middleware(), logger.AccessLog(), i18n.Middleware())
+
+	registerValidator()
+
+	// probe ของ orchestrator: /healthz = process ยังอยู่, /readyz = รับ traffic ได้, /health = รายงานละเอียด
+	// (อยู่นอก /api จึงไม่ผ่าน timeout/idempotency — แต่ละ check มี timeout ของตัวเอง)
+	healthRegistry := options.Health
+	if healthRegistry == nil {
+		healthRegistry = health.NewRegistry(nil, 0, 0)
+	}
+	r.GET("/healthz", health.Liveness())
+	r.GET("/readyz", health.ReadinessHandler(healthRegistry))
+	r.GET("/health", health.ReportHandler(healthRegistry))
+	// Prometheus scrape
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// ผู้ดูแลระบบ: ปรับระดับ log ขณะรัน, ค้น log และ tail แบบสด
+	// (ผลของ /admin/logs คือ log เอง จึงไม่เก็บ body ลง access log ซ้ำ)
+	if options.AdminToken != "" {
+		adminGroup := r.Group("/admin", middleware.AdminAuth(options.AdminToken))
+		adminGroup.GET("/log-levels", admin.GetLogLevels())
+		adminGroup.PUT("/log-levels", admin.UpdateLogLevels())
+		adminGroup.GET("/logs", logger.SkipBodyLogging(), admin.SearchLogs())
+		adminGroup.GET("/logs/tail", logger.SkipBodyLogging(), admin.TailLogs())
+	}
 
 	// v1 -> ต้องเรียก v1.* เท่านั้น
-	apiV1 := r.Group("/api/v1")
+	// POST ทุกเส้นในแต่ละ group รองรับ Idempotency-Key (error ตอบตามรูปแบบของเวอร์ชันนั้น)
+	readTimeout := middleware.Timeout(options.ReadTimeout)
+	writeTimeout := middleware.Timeout(options.WriteTimeout)
+
+	apiV1 := r.Group("/api/v1", middleware.Idempotency(options.IdempotencyRepository, options.IdempotencyTTL, v1.RespondError))
 	{
-		apiV1.GET("/books", v1.GetBooks(bookService))
-		apiV1.GET("/books/:id", v1.GetBook(bookService))
-		apiV1.POST("/books", v1.CreateBook(bookService))
-		apiV1.PUT("/books/:id", v1.UpdateBook(bookService))
-		apiV1.DELETE("/books/:id", v1.DeleteBook(bookService))
+		apiV1.GET("/books", readTimeout, v1.GetBooks(bookService))
+		apiV1.GET("/books/:id", readTimeout, v1.GetBook(bookService))
+		apiV1.POST("/books", writeTimeout, v1.CreateBook(bookService))
+		apiV1.PUT("/books/:id", writeTimeout, v1.UpdateBook(bookService))
+		apiV1.DELETE("/books/:id", writeTimeout, v1.DeleteBook(bookService))
 	}
 
 	// v2 -> ต้องเรียก v2.* เท่านั้น
-	apiV2 := r.Group("/api/v2")
+	apiV2 := r.Group("/api/v2", middleware.Idempotency(options.IdempotencyRepository, options.IdempotencyTTL, problem.Respond))
 	{
-		apiV2.GET("/books", v2.GetBooks(bookService))
-		apiV2.GET("/books/:id", v2.GetBook(bookService))
-		apiV2.POST("/books", v2.CreateBook(bookService))
-		apiV2.PUT("/books/:id", v2.UpdateBook(bookService))
-		apiV2.DELETE("/books/:id", v2.DeleteBook(bookService))
+		apiV2.GET("/books", readTimeout, v2.GetBooks(bookService))
+		apiV2.GET("/books/:id", readTimeout, v2.GetBook(bookService))
+		apiV2.POST("/books", writeTimeout, v2.CreateBook(bookService))
+		apiV2.PUT("/books/:id", writeTimeout, v2.UpdateBook(bookService))
+		apiV2.DELETE("/books/:id", writeTimeout, v2.DeleteBook(bookService))
 	}
 	return r
 }
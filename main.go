@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
@@ -13,26 +22,172 @@ import (
 	_ "github.com/nuba55yo/go-101-BasicCRUD/docs/v2"
 
 	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/migrations"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
 	"github.com/nuba55yo/go-101-BasicCRUD/http/router"
 	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/cache"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/health"
 	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/metrics"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/tracing"
 	"github.com/nuba55yo/go-101-BasicCRUD/repository"
 	"github.com/nuba55yo/go-101-BasicCRUD/service"
 )
 
+// ค่าของ flag --storage
+const (
+	storageDatabase = "db"
+	storageMemory   = "memory"
+)
+
 func main() {
-	defer logger.Close()
+	// โหลด .env ก่อนอ่านค่าใดๆ (โหมด memory ไม่ได้ผ่าน database.Connect)
+	_ = godotenv.Load()
+	// LOG_FORMAT=text|json, LOG_LEVEL=info, LOG_LEVEL_MODULES=books=warn,api=debug
+	if err := logger.SetFormat(os.Getenv("LOG_FORMAT")); err != nil {
+		log.Fatal(err)
+	}
+	levels, err := logger.ParseLevels(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_LEVEL_MODULES"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.SetLevels(levels)
+	// LOG_REDACT_FIELDS, LOG_HEADERS_ALLOW/DENY, LOG_REDACT_REGEX, LOG_BODY_SKIP_ROUTES (ดู logger.RedactionFromEnv)
+	redaction, err := logger.RedactionFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.ConfigureRedaction(redaction)
+	// LOG_DIR, LOG_ROTATE_INTERVAL, LOG_ROTATE_MAX_SIZE, LOG_COMPRESS, LOG_MAX_AGE, LOG_MAX_TOTAL_SIZE, LOG_LATEST_LINK
+	logger.SetRotation(logger.RotationFromEnv())
+	// LOG_SINKS=file,stdout:info,syslog:warn,http:error (ค่าของแต่ละ sink ดู logger.SinksFromEnv) — ตั้งก่อน SetAsync
+	sinks, err := logger.SinksFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger.SetSinks(sinks)
+	// LOG_ASYNC, LOG_ASYNC_BUFFER, LOG_ASYNC_OVERFLOW=block|drop|sample, LOG_ASYNC_SAMPLE
+	if err := logger.SetAsync(logger.AsyncFromEnv()); err != nil {
+		log.Fatal(err)
+	}
 
-	// DB + AutoMigrate
-	database.Connect()
-	if err := database.DB.AutoMigrate(&models.Book{}); err != nil {
+	// go run . migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(os.Args[2:])
+		// ปิด DB (defer ใน runMigrate) และ flush log ก่อน log.Fatal ซึ่งข้าม defer
+		logger.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// --storage=memory เก็บข้อมูลในหน่วยความจำ (เดโม/ทดสอบ ไม่ต้องมี DB ข้อมูลหายเมื่อปิดโปรแกรม)
+	storage := flag.String("storage", storageDatabase, "ที่เก็บข้อมูล: db | memory")
+	flag.Parse()
+
+	// log.Fatal ข้าม defer — จึงแยกงานจริงไว้ใน run ให้ปิด DB/flush log ครบก่อนออก
+	if err := run(*storage); err != nil {
 		log.Fatal(err)
 	}
+}
+
+// run ต่อ dependency แล้วเปิด HTTP server จนได้ SIGINT/SIGTERM จากนั้นปิดทุกอย่างตามลำดับ (ดู shutdown ใน server.go)
+func run(storage string) error {
+	defer logger.Close()
+
+	// ctx ถูกยกเลิกเมื่อได้ SIGINT/SIGTERM — ระหว่างเริ่มต้น (เช่น รอ DB) ก็ยกเลิกได้
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// tracing (TRACING_EXPORTER) — shutdown ส่ง span ที่ค้างออกก่อน flush log
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushContext, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushContext); err != nil {
+			log.Printf("shutdown tracing: %v", err)
+		}
+	}()
+
+	// health check: ผลเก็บไว้ HEALTH_CACHE_TTL กัน probe ยิงฐานข้อมูลถี่เกินไป
+	readiness := health.NewReadiness()
+	healthRegistry := health.NewRegistry(readiness, durationFromEnv("HEALTH_CACHE_TTL", 2*time.Second), durationFromEnv("HEALTH_CHECK_TIMEOUT", 2*time.Second))
+	healthRegistry.Register("log_dir", func(context.Context) error { return logger.CheckWritable() })
+	metrics.RegisterLogWriter(logger.DroppedLines, logger.QueuedLines)
 
 	// DI
-	bookRepo := repository.NewBookRepository(database.DB)
-	bookSvc := service.NewBookService(bookRepo)
-	httpRouter := router.New(bookSvc)
+	var (
+		bookRepo        repository.BookRepository
+		idempotencyRepo repository.IdempotencyRepository
+		txManager       repository.TxManager
+	)
+	switch storage {
+	case storageMemory:
+		log.Print("storage=memory: data is kept in memory and lost on exit")
+		bookRepo = repository.NewMemoryBookRepository()
+		idempotencyRepo = repository.NewMemoryIdempotencyRepository()
+		txManager = repository.NewMemoryTxManager()
+	case storageDatabase:
+		// DB + migration (schema มาจาก database/migrations เป็นหลัก)
+		db, err := database.Connect(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to connect database: %w", err)
+		}
+		// ปิด pool หลัง server หยุดรับ request แล้ว (defer ทำงานย้อนลำดับ)
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("close database: %v", err)
+			}
+		}()
+		if err := migrateOnStart(ctx, db); err != nil {
+			return err
+		}
+		// AutoMigrate เฉพาะตอนพัฒนา (DB_AUTO_MIGRATE=true) — ห้ามเปิดใน production
+		if boolFromEnv("DB_AUTO_MIGRATE", false) {
+			if err := db.DB().AutoMigrate(&models.Book{}, &models.IdempotencyKey{}); err != nil {
+				return err
+			}
+		}
+		if err := registerDatabaseChecks(healthRegistry, db); err != nil {
+			return err
+		}
+		metrics.MustRegister(db.PoolCollector())
+		bookRepo = repository.NewBookRepository(db.DB(), db)
+		idempotencyRepo = repository.NewIdempotencyRepository(db.DB())
+		txManager = repository.NewTxManager(db.DB())
+	default:
+		return fmt.Errorf("unknown --storage=%q (want %s or %s)", storage, storageDatabase, storageMemory)
+	}
+	bookSvc := service.NewBookService(bookRepo, txManager)
+	// cache ของ GetAll/ListVersion/GetByID (BOOK_CACHE_SIZE=0 = ปิด)
+	if size := intFromEnv("BOOK_CACHE_SIZE", 1000); size > 0 {
+		cachedSvc := service.NewCachedBookService(bookSvc, cache.NewLRU(size), durationFromEnv("BOOK_CACHE_TTL", time.Minute))
+		metrics.RegisterCache("books", func() (hits, misses uint64) {
+			stats := cachedSvc.Stats()
+			return stats.Hits, stats.Misses
+		})
+		bookSvc = cachedSvc
+	}
+	// span ต่อเมธอดครอบชั้นนอกสุด — อ่านที่ hit cache ก็มี span (แต่ไม่มี span ของ GORM ข้างใต้)
+	bookSvc = service.NewTracedBookService(bookSvc)
+	httpRouter := router.New(bookSvc, router.Options{
+		IdempotencyRepository: idempotencyRepo,
+		IdempotencyTTL:        durationFromEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		ReadTimeout:           durationFromEnv("ROUTE_READ_TIMEOUT", 5*time.Second),
+		WriteTimeout:          durationFromEnv("ROUTE_WRITE_TIMEOUT", 10*time.Second),
+		Health:                healthRegistry,
+		AdminToken:            os.Getenv("ADMIN_TOKEN"),
+	})
+
+	// งานเบื้องหลัง หยุดผ่าน jobsContext ตอน shutdown (หลัง server ปิดแล้ว)
+	jobs := newBackgroundJobs()
+	// ลบ Idempotency-Key ที่หมดอายุเป็นระยะ
+	jobs.Go(func(ctx context.Context) { middleware.RunIdempotencyCleanup(ctx, idempotencyRepo, time.Hour) })
 
 	// ---------- เสิร์ฟสเปค (doc.json) แยกเวอร์ชัน ----------
 	// อย่าลบ InstanceName ออก เพื่อแยก v1/v2 ให้ชัดเจน
@@ -56,7 +211,62 @@ func main() {
 	if port == "" {
 		port = "8080"
 	}
-	_ = httpRouter.Run(":" + port)
+	return serve(ctx, newHTTPServer(":"+port, httpRouter), readiness, jobs)
+}
+
+// registerDatabaseChecks เพิ่ม check ของฐานข้อมูล: ping primary, migration ครบ (จำเป็น) และ replica (ไม่จำเป็น — ล้มแล้วอ่านจาก primary แทน)
+func registerDatabaseChecks(registry *health.Registry, db *database.Handle) error {
+	migrator, err := database.NewMigrator(db.DB(), migrations.FS)
+	if err != nil {
+		return err
+	}
+	registry.Register("database", db.Ping)
+	registry.Register("migrations", func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending, next %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	})
+	registry.RegisterOptional("replicas", func(context.Context) error {
+		if unhealthy := db.UnhealthyReplicas(); len(unhealthy) > 0 {
+			return fmt.Errorf("unhealthy: %s", strings.Join(unhealthy, ", "))
+		}
+		return nil
+	})
+	return nil
+}
+
+// durationFromEnv อ่าน env แบบ time.ParseDuration (เช่น "24h", "30m") ถ้าไม่ตั้งหรือผิดรูปแบบใช้ค่า fallback
+// ค่า "0" ผ่านได้ (ใช้เป็น "ไม่จำกัด" สำหรับ timeout)
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		log.Printf("invalid %s=%q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// intFromEnv อ่าน env เป็นจำนวนเต็มไม่ติดลบ ถ้าไม่ตั้งหรือผิดรูปแบบใช้ค่า fallback
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("invalid %s=%q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return value
 }
 
 // swaggerIndex คืน HTML ของ Swagger UI (ใช้ CDN) และมี dropdown v1/v2
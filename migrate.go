@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/migrations"
+)
+
+// migrationsDir โฟลเดอร์ซอร์สของไฟล์ migration (มีโฟลเดอร์ย่อยตาม driver)
+// ใช้กับ `migrate create` เท่านั้น ตอนรันอ่านจากไฟล์ที่ฝังไว้
+const migrationsDir = "database/migrations"
+
+const migrateUsage = `usage: go run . migrate <command>
+  up             รันทุกเวอร์ชันที่ยังไม่ได้รัน
+  down [steps]   ย้อนเวอร์ชันล่าสุด (ค่าเริ่มต้น 1 เวอร์ชัน)
+  status         แสดงสถานะทุกเวอร์ชัน
+  create <name>  สร้างไฟล์ up/down ใหม่ของทุก driver ใน ` + migrationsDir
+
+// runMigrate คำสั่ง `migrate up|down|status|create` คืน error แทน log.Fatal ให้ defer ปิด DB ได้ก่อนจบโปรแกรม
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		created, err := database.CreateMigrationFiles(migrationsDir, args[1])
+		for _, path := range created {
+			fmt.Println("created", path)
+		}
+		if err != nil {
+			return fmt.Errorf("create migration: %w", err)
+		}
+		return nil
+	}
+
+	db, err := database.Connect(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	defer db.Close()
+	migrator, err := database.NewMigrator(db.DB(), migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("up   %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New("steps must be a positive integer")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("down %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-32s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// migrateOnStart รัน migration ที่ค้างตอนเริ่ม server (ปิดได้ด้วย DB_MIGRATE_ON_START=false แล้วรัน `migrate up` เอง)
+func migrateOnStart(ctx context.Context, db *database.Handle) error {
+	if !boolFromEnv("DB_MIGRATE_ON_START", true) {
+		return nil
+	}
+	migrator, err := database.NewMigrator(db.DB(), migrations.FS)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		log.Printf("migration applied: %04d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate up failed: %w", err)
+	}
+	return nil
+}
+
+// boolFromEnv อ่าน env แบบ strconv.ParseBool (true/false/1/0) ถ้าไม่ตั้งหรือผิดรูปแบบใช้ค่า fallback
+func boolFromEnv(key string, fallback bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("invalid %s=%q, using %t", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey เก็บผลลัพธ์ของ request ที่มี header Idempotency-Key ไว้ตอบซ้ำเมื่อ client retry
+// Status = 0 หมายถึง request แรกยังทำงานไม่เสร็จ (in-flight)
+type IdempotencyKey struct {
+	Scope        string `gorm:"primaryKey;size:255"`                        // "METHOD path" เช่น "POST /api/v2/books"
+	Key          string `gorm:"column:idempotency_key;primaryKey;size:255"` // ค่าจาก header Idempotency-Key
+	Fingerprint  string `gorm:"size:64;not null"`                           // sha256 ของ method + path + body
+	Status       int    `gorm:"not null;default:0"`
+	ContentType  string `gorm:"size:255"`
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,15 @@
+// Package cache ที่เก็บค่าชั่วคราวแบบมี TTL — ใน process (LRU) หรือ backend ภายนอก (เช่น Redis) ที่ implement Cache เอง
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache สัญญาของ backend: เก็บค่าเป็น []byte ผู้เรียก encode/decode เอง (ใช้กับ backend ข้ามเครื่องได้)
+// error หมายถึง backend มีปัญหา (ไม่ใช่ไม่พบ) ผู้เรียกควรข้าม cache ไปอ่านแหล่งจริงแทน
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU cache ใน process จำกัดจำนวน entry ตัวที่ไม่ได้ใช้นานสุดถูกเอาออกก่อน และแต่ละ entry หมดอายุตาม TTL
+type LRU struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List // หน้าสุด = ใช้ล่าสุด
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero = ไม่หมดอายุ
+}
+
+// NewLRU คืน LRU ที่เก็บได้สูงสุด capacity entry (อย่างน้อย 1)
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+		now:      time.Now,
+	}
+}
+
+func (lru *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	element, ok := lru.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !lru.now().Before(entry.expiresAt) {
+		lru.remove(element)
+		return nil, false, nil
+	}
+	lru.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set เก็บ value (ttl <= 0 = ไม่หมดอายุ) ถ้าเต็มจะเอาตัวที่ไม่ได้ใช้นานสุดออก
+func (lru *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = lru.now().Add(ttl)
+	}
+	if element, ok := lru.entries[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expiresAt: expiresAt}
+		lru.order.MoveToFront(element)
+		return nil
+	}
+	lru.entries[key] = lru.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for lru.order.Len() > lru.capacity {
+		lru.remove(lru.order.Back())
+	}
+	return nil
+}
+
+func (lru *LRU) Delete(_ context.Context, keys ...string) error {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+	for _, key := range keys {
+		if element, ok := lru.entries[key]; ok {
+			lru.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len จำนวน entry ที่เก็บอยู่ (รวมตัวที่หมดอายุแต่ยังไม่ถูกอ่าน)
+func (lru *LRU) Len() int {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+	return lru.order.Len()
+}
+
+func (lru *LRU) remove(element *list.Element) {
+	lru.order.Remove(element)
+	delete(lru.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, lru *LRU, key string) (string, bool) {
+	t.Helper()
+	value, found, err := lru.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(value), found
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+	_ = lru.Set(ctx, "a", []byte("1"), 0)
+	_ = lru.Set(ctx, "b", []byte("2"), 0)
+	// อ่าน a → b กลายเป็นตัวที่ไม่ได้ใช้นานสุด
+	if value, found := get(t, lru, "a"); !found || value != "1" {
+		t.Fatalf("a = %q, %v; want 1", value, found)
+	}
+	_ = lru.Set(ctx, "c", []byte("3"), 0)
+
+	if _, found := get(t, lru, "b"); found {
+		t.Error("b still cached, want evicted")
+	}
+	for key, want := range map[string]string{"a": "1", "c": "3"} {
+		if value, found := get(t, lru, key); !found || value != want {
+			t.Errorf("%s = %q, %v; want %s", key, value, found, want)
+		}
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Len = %d, want 2", lru.Len())
+	}
+
+	// Set key เดิมแทนค่าและนับเป็นการใช้ล่าสุด ไม่เพิ่มจำนวน
+	_ = lru.Set(ctx, "a", []byte("10"), 0)
+	_ = lru.Set(ctx, "d", []byte("4"), 0)
+	if _, found := get(t, lru, "c"); found {
+		t.Error("c still cached, want evicted after a was overwritten")
+	}
+	if value, found := get(t, lru, "a"); !found || value != "10" {
+		t.Errorf("a = %q, %v; want 10", value, found)
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 8, 9, 5, 0, 0, 0, time.UTC)
+	lru := NewLRU(10)
+	lru.now = func() time.Time { return now }
+
+	_ = lru.Set(ctx, "short", []byte("1"), time.Minute)
+	_ = lru.Set(ctx, "forever", []byte("2"), 0)
+
+	now = now.Add(59 * time.Second)
+	if _, found := get(t, lru, "short"); !found {
+		t.Fatal("short expired before its TTL")
+	}
+	now = now.Add(time.Second)
+	if _, found := get(t, lru, "short"); found {
+		t.Error("short still cached at its TTL, want expired")
+	}
+	if lru.Len() != 1 {
+		t.Errorf("Len = %d, want 1 (expired entry removed on read)", lru.Len())
+	}
+	now = now.Add(24 * time.Hour)
+	if _, found := get(t, lru, "forever"); !found {
+		t.Error("entry without TTL expired")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(10)
+	_ = lru.Set(ctx, "a", []byte("1"), 0)
+	_ = lru.Set(ctx, "b", []byte("2"), 0)
+	_ = lru.Set(ctx, "c", []byte("3"), 0)
+
+	// key ที่ไม่มีอยู่ถูกข้ามไป
+	if err := lru.Delete(ctx, "a", "c", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"a": false, "b": true, "c": false} {
+		if _, found := get(t, lru, key); found != want {
+			t.Errorf("%s found = %v, want %v", key, found, want)
+		}
+	}
+	if lru.Len() != 1 {
+		t.Errorf("Len = %d, want 1", lru.Len())
+	}
+}
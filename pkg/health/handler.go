@@ -0,0 +1,45 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Liveness handler ของ /healthz — process ยังตอบได้ (ไม่ตรวจ dependency กัน orchestrator restart ตอน DB ล่ม)
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	}
+}
+
+// ReadinessHandler handler ของ /readyz — 200 เมื่อ check ที่จำเป็นผ่านทั้งหมด
+// 503 เมื่อมีตัวล้มหรือกำลังปิดโปรแกรม (ตอบเฉพาะชื่อ check ที่ล้ม รายละเอียดดู /health)
+func ReadinessHandler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Run(c.Request.Context())
+		if !report.Ready() {
+			failed := []string{}
+			for _, result := range report.Checks {
+				if result.Critical && result.Status != StatusUp {
+					failed = append(failed, result.Name)
+				}
+			}
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": report.Status, "failed": failed})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	}
+}
+
+// ReportHandler handler ของ /health — รายงานทุก check พร้อม latency (503 เมื่อไม่ ready)
+func ReportHandler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Run(c.Request.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
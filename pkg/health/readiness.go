@@ -0,0 +1,19 @@
+// Package health สถานะของ service สำหรับ probe ของ orchestrator (เช่น Kubernetes)
+package health
+
+import "sync/atomic"
+
+// Readiness พร้อมรับ traffic หรือไม่ — เริ่มต้นพร้อม และเปลี่ยนเป็นไม่พร้อมตอนเริ่ม shutdown
+// ให้ load balancer หยุดส่ง request ใหม่มาก่อนที่ server จะปิดจริง
+type Readiness struct {
+	shuttingDown atomic.Bool
+}
+
+// NewReadiness คืน Readiness ที่พร้อมรับ traffic
+func NewReadiness() *Readiness { return &Readiness{} }
+
+// StartShutdown ทำเครื่องหมายว่ากำลังปิด (/readyz และ /health จะตอบ 503 ตั้งแต่นี้)
+func (readiness *Readiness) StartShutdown() { readiness.shuttingDown.Store(true) }
+
+// ShuttingDown true หลังเรียก StartShutdown
+func (readiness *Readiness) ShuttingDown() bool { return readiness.shuttingDown.Load() }
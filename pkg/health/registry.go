@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// สถานะของ check แต่ละตัวและของทั้งระบบ
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusDegraded     = "degraded"      // check ที่ไม่จำเป็นล้ม แต่ยังรับ traffic ได้
+	StatusShuttingDown = "shutting_down" // กำลังปิด (ไม่รัน check)
+)
+
+// CheckFunc ตรวจ dependency หนึ่งตัว คืน nil ถ้าปกติ — ต้องเคารพ deadline ของ ctx
+type CheckFunc func(ctx context.Context) error
+
+// Result ผลของ check หนึ่งตัว
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report ผลรวมของทุก check
+//   - up: ทุกตัวปกติ
+//   - degraded: มีเฉพาะ check ที่ไม่จำเป็นล้ม (ยัง ready)
+//   - down: check ที่จำเป็นล้มอย่างน้อยหนึ่งตัว (ไม่ ready)
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Ready true ถ้ารับ traffic ได้
+func (report Report) Ready() bool {
+	return report.Status == StatusUp || report.Status == StatusDegraded
+}
+
+type registeredCheck struct {
+	name     string
+	critical bool
+	check    CheckFunc
+}
+
+// Registry ที่รวม check ของแต่ละระบบย่อย (DB, migration, log ...) ให้ /readyz และ /health เรียกใช้
+// ผลถูกเก็บไว้ CacheTTL — probe ถี่แค่ไหนก็ยิง dependency ไม่เกินรอบละครั้ง
+type Registry struct {
+	readiness *Readiness
+	cacheTTL  time.Duration
+	timeout   time.Duration
+
+	mutex    sync.RWMutex
+	checks   []registeredCheck
+	cached   *Report
+	cachedAt time.Time
+
+	// runs รวม probe ที่มาพร้อมกันตอน cache หมดอายุให้รัน check ชุดเดียว
+	runs singleflight.Group
+}
+
+// NewRegistry สร้าง Registry — cacheTTL อายุของผลที่เก็บไว้ (0 = ไม่เก็บ), timeout เวลาสูงสุดต่อ check
+// readiness (ถ้ามี) ทำให้รายงาน shutting_down ระหว่างปิดโปรแกรมโดยไม่รัน check
+func NewRegistry(readiness *Readiness, cacheTTL, timeout time.Duration) *Registry {
+	return &Registry{readiness: readiness, cacheTTL: cacheTTL, timeout: timeout}
+}
+
+// Register เพิ่ม check ที่จำเป็น — ล้มแล้วไม่ ready
+func (registry *Registry) Register(name string, check CheckFunc) {
+	registry.add(registeredCheck{name: name, critical: true, check: check})
+}
+
+// RegisterOptional เพิ่ม check ที่ไม่จำเป็น — ล้มแล้วรายงาน degraded แต่ยัง ready
+func (registry *Registry) RegisterOptional(name string, check CheckFunc) {
+	registry.add(registeredCheck{name: name, critical: false, check: check})
+}
+
+func (registry *Registry) add(check registeredCheck) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.checks = append(registry.checks, check)
+	registry.cached = nil
+}
+
+// ShuttingDown true ถ้ากำลังปิดโปรแกรม
+func (registry *Registry) ShuttingDown() bool {
+	return registry.readiness != nil && registry.readiness.ShuttingDown()
+}
+
+// Run คืนผลล่าสุดที่ยังไม่หมดอายุ หรือรันทุก check พร้อมกันแล้วเก็บผลไว้
+func (registry *Registry) Run(ctx context.Context) Report {
+	if registry.ShuttingDown() {
+		return Report{Status: StatusShuttingDown, CheckedAt: time.Now(), Checks: []Result{}}
+	}
+
+	registry.mutex.RLock()
+	cached, cachedAt := registry.cached, registry.cachedAt
+	registry.mutex.RUnlock()
+	if cached != nil && time.Since(cachedAt) < registry.cacheTTL {
+		return *cached
+	}
+
+	// ไม่ผูกกับ ctx ของ request แรก — probe ที่ยกเลิกไม่ควรทำให้ตัวอื่นที่รออยู่ได้ผลล้ม
+	result, _, _ := registry.runs.Do("run", func() (any, error) {
+		report := registry.runChecks(context.WithoutCancel(ctx))
+		registry.mutex.Lock()
+		registry.cached, registry.cachedAt = &report, report.CheckedAt
+		registry.mutex.Unlock()
+		return report, nil
+	})
+	return result.(Report)
+}
+
+func (registry *Registry) runChecks(ctx context.Context) Report {
+	registry.mutex.RLock()
+	checks := append([]registeredCheck(nil), registry.checks...)
+	registry.mutex.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wait sync.WaitGroup
+	for index, check := range checks {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			results[index] = registry.runCheck(ctx, check)
+		}()
+	}
+	wait.Wait()
+
+	report := Report{Status: StatusUp, CheckedAt: time.Now(), Checks: results}
+	for _, result := range results {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// runCheck รัน check ตัวเดียวภายใต้ timeout — panic ถือว่าล้ม ไม่ทำให้ probe ตาย
+func (registry *Registry) runCheck(ctx context.Context, check registeredCheck) (result Result) {
+	result = Result{Name: check.name, Status: StatusUp, Critical: check.critical}
+	if registry.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, registry.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Status, result.Error = StatusDown, fmt.Sprintf("panic: %v", recovered)
+		}
+		result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	}()
+	if err := check.check(ctx); err != nil {
+		result.Status, result.Error = StatusDown, err.Error()
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/health"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func result(t *testing.T, report health.Report, name string) health.Result {
+	t.Helper()
+	for _, result := range report.Checks {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("no result for check %q in %+v", name, report)
+	return health.Result{}
+}
+
+// check ที่ไม่ตอบต้องถูกตัดตาม timeout ของ Registry และนับเป็น down (ไม่ทำให้ probe ค้าง)
+func TestRegistryCheckTimeout(t *testing.T) {
+	registry := health.NewRegistry(nil, 0, 20*time.Millisecond)
+	registry.Register("hung", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	registry.Register("fast", func(context.Context) error { return nil })
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run took %s, want about the 20ms check timeout", elapsed)
+	}
+	if report.Status != health.StatusDown || report.Ready() {
+		t.Errorf("status = %s ready = %v, want down and not ready", report.Status, report.Ready())
+	}
+	if hung := result(t, report, "hung"); hung.Status != health.StatusDown || hung.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("hung = %+v, want down with deadline exceeded", hung)
+	}
+	if fast := result(t, report, "fast"); fast.Status != health.StatusUp {
+		t.Errorf("fast = %+v, want up", fast)
+	}
+}
+
+func TestRegistryCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	registry := health.NewRegistry(nil, 50*time.Millisecond, time.Second)
+	registry.Register("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for range 5 {
+		registry.Run(context.Background())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("check ran %d times within the cache TTL, want 1", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	registry.Run(context.Background())
+	if got := calls.Load(); got != 2 {
+		t.Errorf("check ran %d times after the cache TTL, want 2", got)
+	}
+}
+
+func TestRegistryOptionalCheckDegrades(t *testing.T) {
+	registry := health.NewRegistry(nil, 0, time.Second)
+	registry.Register("db", func(context.Context) error { return nil })
+	registry.RegisterOptional("log_sink", func(context.Context) error { return errors.New("syslog unreachable") })
+	registry.RegisterOptional("panics", func(context.Context) error { panic("boom") })
+
+	report := registry.Run(context.Background())
+	if report.Status != health.StatusDegraded || !report.Ready() {
+		t.Errorf("status = %s ready = %v, want degraded but ready", report.Status, report.Ready())
+	}
+	if panics := result(t, report, "panics"); panics.Status != health.StatusDown || panics.Error != "panic: boom" {
+		t.Errorf("panics = %+v, want down with the panic message", panics)
+	}
+}
+
+// liveness ไม่ดู dependency ส่วน readiness ตอบ 503 พร้อมชื่อ check ที่จำเป็นและล้ม
+func TestHandlers(t *testing.T) {
+	readiness := health.NewReadiness()
+	registry := health.NewRegistry(readiness, 0, time.Second)
+	registry.Register("database", func(context.Context) error { return errors.New("connection refused") })
+	registry.RegisterOptional("log_sink", func(context.Context) error { return errors.New("slow") })
+	registry.Register("migrations", func(context.Context) error { return nil })
+
+	engine := gin.New()
+	engine.GET("/healthz", health.Liveness())
+	engine.GET("/readyz", health.ReadinessHandler(registry))
+	engine.GET("/health", health.ReportHandler(registry))
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	if recorder := get("/healthz"); recorder.Code != http.StatusOK {
+		t.Errorf("/healthz = %d, want 200 even though the database is down", recorder.Code)
+	}
+
+	recorder := get("/readyz")
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz = %d, want 503", recorder.Code)
+	}
+	var readyBody struct {
+		Status string   `json:"status"`
+		Failed []string `json:"failed"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &readyBody); err != nil {
+		t.Fatal(err)
+	}
+	// ไม่รวม check ที่ไม่จำเป็น และไม่ส่งข้อความ error ออกไป
+	if readyBody.Status != health.StatusDown || len(readyBody.Failed) != 1 || readyBody.Failed[0] != "database" {
+		t.Errorf("/readyz body = %s, want down with failed [database]", recorder.Body)
+	}
+
+	recorder = get("/health")
+	var report health.Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusServiceUnavailable || len(report.Checks) != 3 || result(t, report, "database").Error != "connection refused" {
+		t.Errorf("/health = %d %s, want 503 with every check and its error", recorder.Code, recorder.Body)
+	}
+
+	// กำลังปิด: ไม่รัน check และตอบ shutting_down
+	readiness.StartShutdown()
+	recorder = get("/readyz")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &readyBody); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusServiceUnavailable || readyBody.Status != health.StatusShuttingDown {
+		t.Errorf("/readyz while shutting down = %d %s, want 503 shutting_down", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,112 @@
+// Package i18n เลือกภาษาจาก Accept-Language และแปลข้อความ error (th/en)
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+// ภาษาที่รองรับ
+const (
+	EN = "en"
+	TH = "th"
+
+	// FallbackLocale ใช้เมื่อ Accept-Language ไม่ตรงกับภาษาที่รองรับเลย
+	FallbackLocale = EN
+)
+
+// key ใน gin.Context ที่เก็บ Localizer ของ request
+const contextKey = "i18n.localizer"
+
+// ลำดับต้องตรงกับ supportedLocales (ตัวแรกคือ fallback ของ matcher)
+var (
+	supportedTags    = []language.Tag{language.English, language.Thai}
+	supportedLocales = []string{EN, TH}
+	matcher          = language.NewMatcher(supportedTags)
+)
+
+// Localizer แปลข้อความของภาษาเดียว ผูกกับ request หนึ่งๆ
+type Localizer struct {
+	locale     string
+	translator ut.Translator
+}
+
+// Negotiate เลือกภาษาจากค่า header Accept-Language (เช่น "th-TH,th;q=0.9,en;q=0.8")
+func Negotiate(acceptLanguage string) *Localizer {
+	return ForLocale(matchLocale(acceptLanguage))
+}
+
+// ForLocale คืน Localizer ของภาษาที่ระบุ (ภาษาที่ไม่รองรับจะใช้ FallbackLocale)
+func ForLocale(locale string) *Localizer {
+	if _, ok := catalog[locale]; !ok {
+		locale = FallbackLocale
+	}
+	translator, _ := universal.GetTranslator(locale)
+	return &Localizer{locale: locale, translator: translator}
+}
+
+func matchLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return FallbackLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return FallbackLocale
+	}
+	return supportedLocales[index]
+}
+
+// Locale คืนรหัสภาษา เช่น "th"
+func (localizer *Localizer) Locale() string { return localizer.locale }
+
+// Translator คืน ut.Translator สำหรับแปล validator.FieldError
+func (localizer *Localizer) Translator() ut.Translator { return localizer.translator }
+
+// T แปลข้อความตาม key (ถ้าภาษานี้ไม่มี key จะลองภาษา fallback แล้วค่อยคืน key เอง)
+func (localizer *Localizer) T(key string, args ...any) string {
+	text, ok := catalog[localizer.locale][key]
+	if !ok {
+		text, ok = catalog[FallbackLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Middleware เลือกภาษาให้ทุก request และตอบ Content-Language กลับไป
+func Middleware() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		localizer := Negotiate(context.GetHeader("Accept-Language"))
+		context.Set(contextKey, localizer)
+		context.Header("Content-Language", localizer.Locale())
+		context.Next()
+	}
+}
+
+// FromContext ดึง Localizer ของ request (ถ้าไม่ได้ผ่าน Middleware จะเลือกจาก header ตรงนี้)
+func FromContext(context *gin.Context) *Localizer {
+	if value, ok := context.Get(contextKey); ok {
+		if localizer, ok := value.(*Localizer); ok {
+			return localizer
+		}
+	}
+	return Negotiate(context.GetHeader("Accept-Language"))
+}
+
+// FieldLabel ชื่อฟิลด์ที่แสดงให้ผู้ใช้ (เช่น title -> "ชื่อหนังสือ") ถ้าไม่มีใน catalog ใช้ชื่อเดิม
+func (localizer *Localizer) FieldLabel(field string) string {
+	key := "field." + strings.ToLower(field)
+	if label := localizer.T(key); label != key {
+		return label
+	}
+	return field
+}
@@ -0,0 +1,124 @@
+package i18n
+
+// key ของข้อความที่ handler ใช้ (ข้อความจริงอยู่ใน catalog ด้านล่าง)
+const (
+	MsgBookListFailed   = "book.list_failed"
+	MsgBookNotFound     = "book.not_found"
+	MsgBookTitleExists  = "book.title_exists"
+	MsgBookInputMissing = "book.input_missing"
+	MsgBookCreateFailed = "book.create_failed"
+	MsgBookUpdateFailed = "book.update_failed"
+	MsgBookDeleteFailed = "book.delete_failed"
+
+	MsgInvalidID                = "request.invalid_id"
+	MsgConflict                 = "request.conflict"
+	MsgIdempotencyKeyInvalid    = "idempotency.key_invalid"
+	MsgIdempotencyKeyReused     = "idempotency.key_reused"
+	MsgIdempotencyKeyInProgress = "idempotency.key_in_progress"
+
+	MsgBodyEmpty        = "request.body_empty"
+	MsgBodyMalformed    = "request.body_malformed"
+	MsgBodyWrongType    = "request.body_wrong_type"
+	MsgBodyInvalid      = "request.body_invalid"
+	MsgFieldWrongType   = "request.field_wrong_type"
+	MsgValidationFailed = "validation.failed"
+	MsgValidationField  = "validation.field_invalid"
+
+	MsgInternal = "internal.unexpected"
+	MsgTimeout  = "internal.timeout"
+	MsgCanceled = "request.canceled"
+
+	TitleValidation    = "title.validation"
+	TitleMalformed     = "title.malformed"
+	TitleTitleExists   = "title.title_exists"
+	TitleConflict      = "title.conflict"
+	TitleNotFound      = "title.not_found"
+	TitleInternal      = "title.internal"
+	TitleUnprocessable = "title.unprocessable"
+	TitleTimeout       = "title.timeout"
+	TitleCanceled      = "title.canceled"
+)
+
+// catalog ข้อความแยกตามภาษา (ค่าที่มี %s/%d ใช้กับ fmt.Sprintf)
+var catalog = map[string]map[string]string{
+	EN: {
+		MsgBookListFailed:   "cannot get books",
+		MsgBookNotFound:     "book not found",
+		MsgBookTitleExists:  "title already exists",
+		MsgBookInputMissing: "title and author are required",
+		MsgBookCreateFailed: "create failed",
+		MsgBookUpdateFailed: "update failed",
+		MsgBookDeleteFailed: "delete failed",
+
+		MsgInvalidID:                "book id must be a positive integer",
+		MsgConflict:                 "the request conflicts with existing data",
+		MsgIdempotencyKeyInvalid:    "Idempotency-Key must be at most 255 characters",
+		MsgIdempotencyKeyReused:     "Idempotency-Key was already used with a different request",
+		MsgIdempotencyKeyInProgress: "a request with this Idempotency-Key is still being processed",
+
+		MsgBodyEmpty:        "request body is empty",
+		MsgBodyMalformed:    "request body is not valid JSON",
+		MsgBodyWrongType:    "request body has a field with the wrong type",
+		MsgBodyInvalid:      "request body is invalid",
+		MsgFieldWrongType:   "%s must be %s",
+		MsgValidationFailed: "one or more fields are invalid",
+		MsgValidationField:  "%s is invalid (%s)",
+
+		MsgInternal: "unexpected error",
+		MsgTimeout:  "the request took too long to process",
+		MsgCanceled: "the request was cancelled by the client",
+
+		TitleValidation:    "Validation failed",
+		TitleMalformed:     "Bad Request",
+		TitleTitleExists:   "Conflict",
+		TitleConflict:      "Conflict",
+		TitleNotFound:      "Not Found",
+		TitleInternal:      "Internal Server Error",
+		TitleUnprocessable: "Unprocessable Entity",
+		TitleTimeout:       "Gateway Timeout",
+		TitleCanceled:      "Client Closed Request",
+
+		"field.title":  "title",
+		"field.author": "author",
+	},
+	TH: {
+		MsgBookListFailed:   "ไม่สามารถดึงรายการหนังสือได้",
+		MsgBookNotFound:     "ไม่พบหนังสือ",
+		MsgBookTitleExists:  "ชื่อหนังสือนี้มีอยู่แล้ว",
+		MsgBookInputMissing: "ต้องระบุชื่อหนังสือและผู้แต่ง",
+		MsgBookCreateFailed: "สร้างหนังสือไม่สำเร็จ",
+		MsgBookUpdateFailed: "แก้ไขหนังสือไม่สำเร็จ",
+		MsgBookDeleteFailed: "ลบหนังสือไม่สำเร็จ",
+
+		MsgInvalidID:                "รหัสหนังสือต้องเป็นจำนวนเต็มบวก",
+		MsgConflict:                 "ข้อมูลขัดแย้งกับข้อมูลที่มีอยู่",
+		MsgIdempotencyKeyInvalid:    "Idempotency-Key ต้องยาวไม่เกิน 255 ตัวอักษร",
+		MsgIdempotencyKeyReused:     "Idempotency-Key นี้ถูกใช้กับคำขออื่นไปแล้ว",
+		MsgIdempotencyKeyInProgress: "คำขอที่ใช้ Idempotency-Key นี้กำลังประมวลผลอยู่",
+
+		MsgBodyEmpty:        "ไม่พบข้อมูลใน request body",
+		MsgBodyMalformed:    "request body ไม่ใช่ JSON ที่ถูกต้อง",
+		MsgBodyWrongType:    "request body มีฟิลด์ที่ชนิดข้อมูลไม่ถูกต้อง",
+		MsgBodyInvalid:      "request body ไม่ถูกต้อง",
+		MsgFieldWrongType:   "%s ต้องเป็นชนิด %s",
+		MsgValidationFailed: "ข้อมูลบางฟิลด์ไม่ถูกต้อง",
+		MsgValidationField:  "%s ไม่ถูกต้อง (%s)",
+
+		MsgInternal: "เกิดข้อผิดพลาดที่ไม่คาดคิด",
+		MsgTimeout:  "ประมวลผลคำขอนานเกินเวลาที่กำหนด",
+		MsgCanceled: "คำขอถูกยกเลิกโดย client",
+
+		TitleValidation:    "ข้อมูลไม่ผ่านการตรวจสอบ",
+		TitleMalformed:     "คำขอไม่ถูกต้อง",
+		TitleTitleExists:   "ข้อมูลซ้ำ",
+		TitleConflict:      "คำขอขัดแย้ง",
+		TitleNotFound:      "ไม่พบข้อมูล",
+		TitleInternal:      "ข้อผิดพลาดภายในระบบ",
+		TitleUnprocessable: "ไม่สามารถประมวลผลคำขอได้",
+		TitleTimeout:       "หมดเวลา",
+		TitleCanceled:      "คำขอถูกยกเลิก",
+
+		"field.title":  "ชื่อหนังสือ",
+		"field.author": "ผู้แต่ง",
+	},
+}
@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/th"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+)
+
+// universal รวม translator ของทุกภาษา (en เป็น fallback)
+var universal = ut.New(en.New(), en.New(), th.New())
+
+// FieldMessage ข้อความ error ของฟิลด์เดียวที่แปลแล้ว
+type FieldMessage struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// RegisterValidator ผูก validator (ตัวเดียวกับที่ gin ใช้ bind) เข้ากับ translator ทุกภาษา
+// และให้ชื่อฟิลด์ใน error ใช้ตาม json tag แทนชื่อ struct field
+func RegisterValidator(validate *validator.Validate) error {
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	enTranslator, _ := universal.GetTranslator(EN)
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTranslator); err != nil {
+		return err
+	}
+	return registerThaiTranslations(validate)
+}
+
+// ValidationErrors แปล error จาก validator เป็นข้อความของภาษานี้
+// คืน false ถ้า err ไม่ใช่ validator.ValidationErrors (เช่น JSON พัง)
+func (localizer *Localizer) ValidationErrors(err error) ([]FieldMessage, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+	messages := make([]FieldMessage, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		message := fieldErr.Translate(localizer.translator)
+		if message == fieldErr.Error() {
+			// ไม่มีคำแปลของ tag นี้ ใช้ข้อความกลางแทนข้อความดิบของ validator
+			message = localizer.T(MsgValidationField, localizer.FieldLabel(fieldErr.Field()), fieldErr.Tag())
+		}
+		messages = append(messages, FieldMessage{Field: fieldErr.Field(), Rule: fieldErr.Tag(), Message: message})
+	}
+	return messages, true
+}
+
+// thaiTranslations ข้อความของ tag ที่ใช้บ่อย ({0} = ชื่อฟิลด์, {1} = พารามิเตอร์ของ tag)
+var thaiTranslations = map[string]string{
+	"required":   "{0} จำเป็นต้องระบุ",
+	"min-string": "{0} ต้องมีความยาวอย่างน้อย {1} ตัวอักษร",
+	"min-number": "{0} ต้องมีค่าอย่างน้อย {1}",
+	"max-string": "{0} ต้องมีความยาวไม่เกิน {1} ตัวอักษร",
+	"max-number": "{0} ต้องมีค่าไม่เกิน {1}",
+	"len-string": "{0} ต้องมีความยาว {1} ตัวอักษร",
+	"len-number": "{0} ต้องมีค่าเท่ากับ {1}",
+	"email":      "{0} ต้องเป็นอีเมลที่ถูกต้อง",
+	"oneof":      "{0} ต้องเป็นค่าใดค่าหนึ่งใน [{1}]",
+}
+
+func registerThaiTranslations(validate *validator.Validate) error {
+	thTranslator, _ := universal.GetTranslator(TH)
+	for key, text := range thaiTranslations {
+		if err := thTranslator.Add(key, text, true); err != nil {
+			return err
+		}
+	}
+
+	thaiLocalizer := &Localizer{locale: TH, translator: thTranslator}
+	translate := func(translator ut.Translator, fieldErr validator.FieldError) string {
+		key := fieldErr.Tag()
+		switch key {
+		case "min", "max", "len":
+			if fieldErr.Kind() == reflect.String {
+				key += "-string"
+			} else {
+				key += "-number"
+			}
+		}
+		message, err := translator.T(key, thaiLocalizer.FieldLabel(fieldErr.Field()), fieldErr.Param())
+		if err != nil {
+			return fieldErr.Error()
+		}
+		return message
+	}
+	noop := func(ut.Translator) error { return nil }
+
+	for _, tag := range []string{"required", "min", "max", "len", "email", "oneof"} {
+		if err := validate.RegisterTranslation(tag, thTranslator, noop, translate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BindErrorMessage ข้อความเดียวสำหรับ error จากการ bind body (ใช้กับ response แบบ {"error": "..."})
+func (localizer *Localizer) BindErrorMessage(err error) string {
+	fields, ok := localizer.ValidationErrors(err)
+	if !ok {
+		return localizer.T(MsgBodyInvalid)
+	}
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
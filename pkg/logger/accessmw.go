@@ -2,7 +2,10 @@ package logger
 
 import (
 	"bytes"
+	stdcontext "context"
+	"errors"
 	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -25,17 +28,49 @@ func moduleFromRoute(route string) string {
 	return parts[0]
 }
 
+// bodyCapture เก็บสำเนาไม่เกิน maxLoggedBody ไบต์ของสิ่งที่ไหลผ่าน (เกินจากนั้นนับไว้แต่ไม่เก็บ)
+type bodyCapture struct {
+	buffer    bytes.Buffer
+	truncated bool
+}
+
+func (capture *bodyCapture) keep(b []byte) {
+	room := maxLoggedBody - capture.buffer.Len()
+	if len(b) > room {
+		b = b[:max(room, 0)]
+		capture.truncated = true
+	}
+	capture.buffer.Write(b)
+}
+
+// requestBodyReader ดัก request body ระหว่างที่ handler อ่าน (handler ยังได้ body ครบทุกไบต์)
+type requestBodyReader struct {
+	io.ReadCloser
+	capture bodyCapture
+}
+
+func (reader *requestBodyReader) Read(b []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(b)
+	reader.capture.keep(b[:n])
+	return n, err
+}
+
 // bodyLogWriter ดัก response body ที่ framework จะส่งออก เพื่อเก็บสำเนาไปลง log
 type bodyLogWriter struct {
 	gin.ResponseWriter
-	buffer bytes.Buffer
+	capture bodyCapture
 }
 
 func (w *bodyLogWriter) Write(b []byte) (int, error) {
-	w.buffer.Write(b) // เก็บสำเนาไว้ใน buffer
+	w.capture.keep(b) // เก็บสำเนาไว้ใน buffer
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap ให้ http.ResponseController เข้าถึง writer จริงได้ (เช่น ยกเลิก write deadline ของ stream)
+func (w *bodyLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func sanitize(s string) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	if len(s) > maxLoggedBody {
@@ -44,19 +79,33 @@ func sanitize(s string) string {
 	return s
 }
 
+// skipBodyKey key ใน gin.Context ที่ SkipBodyLogging ตั้งไว้
+const skipBodyKey = "logger.skipBody"
+
+// SkipBodyLogging ใส่ราย route/group เพื่อไม่เก็บ request/response body ลง access log (เช่น login, อัปโหลดไฟล์)
+// ใช้คู่กับ LOG_BODY_SKIP_ROUTES ได้ — อันใดอันหนึ่งตรงก็ข้าม
+func SkipBodyLogging() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Set(skipBodyKey, true)
+		context.Next()
+	}
+}
+
 // AccessLog เขียน log ทั้ง request + response ทุกสถานะ
-// 2xx → info, 4xx → warn, 5xx → error
+// 2xx → info, 4xx → warn, 5xx → error (มี request_id ถ้าวาง middleware.RequestID ไว้ก่อน)
+// body และ header ผ่านกติกาของ ConfigureRedaction ก่อนลง log เสมอ
 func AccessLog() gin.HandlerFunc {
 	return func(context *gin.Context) {
 		start := time.Now()
+		// ctx ของ server: ถูกยกเลิกเมื่อ client ตัดการเชื่อมต่อ (ไม่ใช่ ctx ที่ middleware.Timeout ครอบทีหลัง)
+		requestContext := context.Request.Context()
+		redactor := currentRedactor.Load()
 
-		// เก็บ request body (จำกัดความยาว)
-		var requestBody string
-		if context.Request != nil && context.Request.Body != nil && context.Request.ContentLength != 0 {
-			raw, _ := io.ReadAll(io.LimitReader(context.Request.Body, maxLoggedBody))
-			requestBody = string(raw)
-			// คืน body กลับให้ handler ใช้ต่อ
-			context.Request.Body = io.NopCloser(bytes.NewBuffer(raw))
+		// ดัก request body ระหว่างที่ handler อ่าน (ไม่อ่านล่วงหน้า body ที่ยาวเกินจึงไม่ถูกตัด)
+		var requestReader *requestBodyReader
+		if context.Request.Body != nil && context.Request.Body != http.NoBody && context.Request.ContentLength != 0 {
+			requestReader = &requestBodyReader{ReadCloser: context.Request.Body}
+			context.Request.Body = requestReader
 		}
 
 		// ดัก response body
@@ -74,20 +123,44 @@ func AccessLog() gin.HandlerFunc {
 		module := moduleFromRoute(route)
 		latency := time.Since(start)
 		errMsg := context.Errors.ByType(gin.ErrorTypeAny).String()
+		skipBody := context.GetBool(skipBodyKey) || redactor.skipBody(context.Request.Method, route)
 
-		requestBody = sanitize(requestBody)
-		responseBody := sanitize(writer.buffer.String())
+		fields := []Field{
+			Int("status", status),
+			String("method", context.Request.Method),
+			String("route", route),
+			String("ip", context.ClientIP()),
+			Milliseconds("latency_ms", latency),
+		}
+		if headers := redactor.headers(context.Request.Header); len(headers) > 0 {
+			fields = append(fields, Any("headers", headers))
+		}
+		if status >= 400 && errMsg != "" {
+			fields = append(fields, String("err", sanitize(redactor.scrub(errMsg))))
+		}
+		if !skipBody && requestReader != nil {
+			requestBody := redactor.body(context.GetHeader("Content-Type"), requestReader.capture.buffer.Bytes(), requestReader.capture.truncated)
+			fields = append(fields, String("req", sanitize(requestBody)))
+		} else if !skipBody {
+			fields = append(fields, String("req", ""))
+		}
 
+		if errors.Is(requestContext.Err(), stdcontext.Canceled) {
+			// client ตัดการเชื่อมต่อก่อนได้คำตอบ แยกออกจาก error ปกติ (ไม่มี response ให้เก็บ)
+			WarnContext(requestContext, module, "cancelled by client", fields...)
+			return
+		}
+		if !skipBody {
+			responseBody := redactor.body(writer.Header().Get("Content-Type"), writer.capture.buffer.Bytes(), writer.capture.truncated)
+			fields = append(fields, String("res", sanitize(responseBody)))
+		}
 		switch {
 		case status >= 500:
-			Errorf(module, "status=%d method=%s route=%s ip=%s latency=%s err=%s req=%s res=%s",
-				status, context.Request.Method, route, context.ClientIP(), latency, sanitize(errMsg), requestBody, responseBody)
+			ErrorContext(context.Request.Context(), module, "request", fields...)
 		case status >= 400:
-			Warnf(module, "status=%d method=%s route=%s ip=%s latency=%s err=%s req=%s res=%s",
-				status, context.Request.Method, route, context.ClientIP(), latency, sanitize(errMsg), requestBody, responseBody)
+			WarnContext(context.Request.Context(), module, "request", fields...)
 		default:
-			Infof(module, "status=%d method=%s route=%s ip=%s latency=%s req=%s res=%s",
-				status, context.Request.Method, route, context.ClientIP(), latency, requestBody, responseBody)
+			InfoContext(context.Request.Context(), module, "request", fields...)
 		}
 	}
 }
@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ค่าของ LOG_ASYNC_OVERFLOW — ทำอย่างไรเมื่อคิวเต็ม (บรรทัดระดับ error รอเสมอ ไม่ถูกทิ้ง)
+const (
+	OverflowBlock  = "block"  // รอจนมีที่ว่าง (ไม่เสีย log แต่ request ช้าลงตาม disk)
+	OverflowDrop   = "drop"   // ทิ้งบรรทัดนั้นแล้วนับไว้ (DroppedLines)
+	OverflowSample = "sample" // เก็บ 1 ใน SampleRate บรรทัด (รอ) ที่เหลือทิ้งแล้วนับไว้
+)
+
+// asyncMaxBatch จำนวนบรรทัดสูงสุดต่อชุดก่อน flush (กันคนเรียก Flush/Close รอนานเกิน)
+const asyncMaxBatch = 512
+
+// AsyncConfig โหมดเขียนแบบ async: คนเรียกแค่ใส่บรรทัดลงคิว goroutine เดียวส่งให้ทุก sink เป็นชุด
+type AsyncConfig struct {
+	Enabled    bool
+	BufferSize int    // จำนวนบรรทัดที่ค้างในคิวได้
+	Overflow   string // block | drop | sample
+	SampleRate int    // ใช้กับ sample: เก็บ 1 ใน N บรรทัดขณะคิวเต็ม
+}
+
+// AsyncFromEnv อ่านค่าจาก env (ไม่ตั้งหรือผิดรูปแบบใช้ค่าเริ่มต้น)
+//
+//	LOG_ASYNC (false), LOG_ASYNC_BUFFER (8192), LOG_ASYNC_OVERFLOW (block), LOG_ASYNC_SAMPLE (10)
+func AsyncFromEnv() AsyncConfig {
+	config := AsyncConfig{
+		Enabled:    envBool("LOG_ASYNC", false),
+		BufferSize: envInt("LOG_ASYNC_BUFFER", 8192),
+		Overflow:   strings.ToLower(strings.TrimSpace(os.Getenv("LOG_ASYNC_OVERFLOW"))),
+		SampleRate: envInt("LOG_ASYNC_SAMPLE", 10),
+	}
+	if config.Overflow == "" {
+		config.Overflow = OverflowBlock
+	}
+	return config
+}
+
+// asyncWriter คิว (ring buffer ขนาดคงที่ของ channel) + goroutine ผู้เขียน
+type asyncWriter struct {
+	config   AsyncConfig
+	queue    chan asyncItem
+	done     chan struct{}
+	overflow atomic.Uint64 // จำนวนครั้งที่เจอคิวเต็ม ใช้เลือกบรรทัดของ sample
+}
+
+// asyncItem หนึ่งบรรทัด หรือคำขอ Flush (record ว่าง + flushed)
+type asyncItem struct {
+	record  *Record
+	flushed chan struct{}
+}
+
+// asyncCurrent ตัวเขียนที่ใช้อยู่ (nil = เขียนตรงแบบเดิม)
+// คนใส่คิวถือ RLock ตลอดการส่ง จึงหยุดตัวเขียน (Lock แล้วปิด channel) ได้โดยไม่มีใครส่งเข้า channel ที่ปิดแล้ว
+var (
+	asyncMutex   sync.RWMutex
+	asyncCurrent *asyncWriter
+	droppedLines atomic.Uint64
+)
+
+// SetAsync เปิด/ปิด/เปลี่ยนค่าโหมด async — ตัวเขียนเดิมจะเขียนบรรทัดที่ค้างให้หมดก่อน
+func SetAsync(config AsyncConfig) error {
+	if config.Enabled {
+		switch config.Overflow {
+		case OverflowBlock, OverflowDrop, OverflowSample:
+		default:
+			return fmt.Errorf("unknown LOG_ASYNC_OVERFLOW=%q (want %s, %s or %s)", config.Overflow, OverflowBlock, OverflowDrop, OverflowSample)
+		}
+		if config.BufferSize <= 0 {
+			return fmt.Errorf("LOG_ASYNC_BUFFER must be positive, got %d", config.BufferSize)
+		}
+		config.SampleRate = max(config.SampleRate, 1)
+	}
+
+	stopAsync()
+	if !config.Enabled {
+		return nil
+	}
+	writer := &asyncWriter{
+		config: config,
+		queue:  make(chan asyncItem, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go writer.run()
+	asyncMutex.Lock()
+	asyncCurrent = writer
+	asyncMutex.Unlock()
+	return nil
+}
+
+// stopAsync ปิดคิว รอเขียนบรรทัดที่ค้างให้หมด แล้วกลับไปเขียนตรง
+func stopAsync() {
+	asyncMutex.Lock()
+	writer := asyncCurrent
+	asyncCurrent = nil
+	asyncMutex.Unlock()
+	if writer != nil {
+		close(writer.queue)
+		<-writer.done
+	}
+}
+
+// enqueue ใส่บรรทัดลงคิวตามนโยบายตอนเต็ม — false = ไม่ได้เปิด async (ให้คนเรียกเขียนเอง)
+func enqueue(record Record) bool {
+	asyncMutex.RLock()
+	defer asyncMutex.RUnlock()
+	writer := asyncCurrent
+	if writer == nil {
+		return false
+	}
+	select {
+	case writer.queue <- asyncItem{record: &record}:
+		return true
+	default:
+	}
+
+	// คิวเต็ม
+	if record.Level < LevelError {
+		switch writer.config.Overflow {
+		case OverflowDrop:
+			droppedLines.Add(1)
+			return true
+		case OverflowSample:
+			if writer.overflow.Add(1)%uint64(writer.config.SampleRate) != 0 {
+				droppedLines.Add(1)
+				return true
+			}
+		}
+	}
+	writer.queue <- asyncItem{record: &record}
+	return true
+}
+
+// run รับบรรทัดแรกแบบรอ แล้วดึงที่ค้างอยู่ต่อทันทีเป็นชุด ส่งให้ทุก sink แล้ว flush ครั้งเดียวต่อชุด
+func (writer *asyncWriter) run() {
+	defer close(writer.done)
+	var reported uint64
+	var reportedAt time.Time
+	for item := range writer.queue {
+		var waiters []chan struct{}
+		for count := 0; ; count++ {
+			if item.record != nil {
+				dispatch(*item.record)
+			}
+			if item.flushed != nil {
+				waiters = append(waiters, item.flushed)
+			}
+			if count+1 >= asyncMaxBatch {
+				break
+			}
+			var ok bool
+			select {
+			case item, ok = <-writer.queue:
+			default:
+			}
+			if !ok {
+				break
+			}
+		}
+		// แจ้งจำนวนบรรทัดที่ถูกทิ้งลง log เอง ไม่เกินวินาทีละครั้ง
+		if dropped := droppedLines.Load(); dropped > reported && time.Since(reportedAt) >= time.Second {
+			dispatch(newRecord("logger", LevelWarn, nil, "lines dropped",
+				[]Field{Int64("dropped", int64(dropped-reported)), Int64("dropped_total", int64(dropped))}))
+			reported, reportedAt = dropped, time.Now()
+		}
+		flushSinks()
+		for _, waiter := range waiters {
+			close(waiter)
+		}
+	}
+}
+
+// Flush รอจนบรรทัดที่อยู่ในคิวก่อนหน้านี้ถูกเขียนลงไฟล์ (โหมดปกติเขียนทันทีอยู่แล้ว จึงคืนเลย)
+func Flush() {
+	asyncMutex.RLock()
+	writer := asyncCurrent
+	if writer == nil {
+		asyncMutex.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	writer.queue <- asyncItem{flushed: flushed}
+	asyncMutex.RUnlock()
+	<-flushed
+}
+
+// DroppedLines จำนวนบรรทัดที่ถูกทิ้งตั้งแต่เริ่มโปรแกรม (คิว async เต็มตามนโยบาย drop/sample
+// หรือ sink ทางเครือข่ายคิวเต็ม/ส่งไม่สำเร็จจนหมดรอบ retry)
+func DroppedLines() uint64 {
+	return droppedLines.Load()
+}
+
+// QueuedLines จำนวนบรรทัดที่รอเขียนอยู่ในคิว (0 ถ้าไม่ได้เปิด async)
+func QueuedLines() int {
+	asyncMutex.RLock()
+	defer asyncMutex.RUnlock()
+	if asyncCurrent == nil {
+		return 0
+	}
+	return len(asyncCurrent.queue)
+}
+
+// envInt อ่าน env เป็นจำนวนเต็มไม่ติดลบ — ผิดรูปแบบใช้ fallback
+func envInt(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		fmt.Fprintf(os.Stderr, "logger: invalid %s=%q, using %d\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package logger_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// slowSink ปลายทางที่ช้ากว่าคนเขียน (เหมือน disk/เครือข่ายช้า): ทุกบรรทัดใช้เวลา write และทุกชุดใช้เวลา flush
+// ใช้ busy-wait แทน time.Sleep เพราะ Sleep ช่วงสั้นระดับไมโครวินาทีไม่แม่น
+type slowSink struct {
+	write, flush time.Duration
+}
+
+func (sink slowSink) Write(logger.Record) error { spin(sink.write); return nil }
+func (sink slowSink) Flush() error              { spin(sink.flush); return nil }
+func (slowSink) Close() error                   { return nil }
+
+func spin(duration time.Duration) {
+	for start := time.Now(); time.Since(start) < duration; {
+	}
+}
+
+// BenchmarkLogger เทียบเวลาที่คนเรียก logger เสีย (ns/op และ p99 ต่อบรรทัด) ระหว่างเขียนตรงกับ async แต่ละนโยบายตอนคิวเต็ม
+// sink ช้ากว่าคนเขียน คิวจึงเต็มจริง: block รอตาม sink, drop/sample ทิ้งบรรทัด (ดู dropped/op)
+//
+//	go test ./pkg/logger -run '^$' -bench Logger -benchtime 200000x
+func BenchmarkLogger(b *testing.B) {
+	modes := []struct {
+		name  string
+		async logger.AsyncConfig
+	}{
+		{"sync", logger.AsyncConfig{}},
+		{"async-block", logger.AsyncConfig{Enabled: true, BufferSize: 1024, Overflow: logger.OverflowBlock}},
+		{"async-drop", logger.AsyncConfig{Enabled: true, BufferSize: 1024, Overflow: logger.OverflowDrop}},
+		{"async-sample", logger.AsyncConfig{Enabled: true, BufferSize: 1024, Overflow: logger.OverflowSample, SampleRate: 10}},
+	}
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			logger.SetSinks([]logger.SinkConfig{{Name: "slow", Minimum: logger.LevelTrace, Sink: slowSink{write: 2 * time.Microsecond, flush: 20 * time.Microsecond}}})
+			if err := logger.SetAsync(mode.async); err != nil {
+				b.Fatal(err)
+			}
+			defer func() {
+				_ = logger.SetAsync(logger.AsyncConfig{})
+				logger.SetSinks(nil)
+			}()
+
+			droppedBefore := logger.DroppedLines()
+			latencies := make([]time.Duration, b.N)
+			b.ResetTimer()
+			for index := range latencies {
+				start := time.Now()
+				logger.Info("bench", "request handled", logger.Int("status", 200))
+				latencies[index] = time.Since(start)
+			}
+			b.StopTimer()
+			logger.Flush()
+
+			slices.Sort(latencies)
+			b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns")
+			b.ReportMetric(float64(logger.DroppedLines()-droppedBefore)/float64(b.N), "dropped/op")
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// รูปแบบของบรรทัด log (LOG_FORMAT)
+const (
+	FormatText = "text" // 2025-08-09 05:01:14.533 [books] [info] request_id=... message key=value ...
+	FormatJSON = "json" // {"time":"...","level":"info","module":"books","request_id":"...","msg":"...","key":"value"}
+)
+
+// entry บรรทัด log หนึ่งบรรทัดก่อนแปลงเป็นข้อความ
+type entry struct {
+	time    time.Time
+	module  string
+	level   string
+	message string
+	context []Field // ค่าจาก ctx (request_id, trace_id, span_id) — text วางไว้หน้าข้อความ
+	fields  []Field
+}
+
+// encoder แปลง entry เป็นหนึ่งบรรทัด (รวม \n ท้าย)
+type encoder func(buffer *bytes.Buffer, line entry)
+
+var currentEncoder atomic.Pointer[encoder]
+
+func init() {
+	text := encoder(encodeText)
+	currentEncoder.Store(&text)
+}
+
+// SetFormat เลือกรูปแบบ log: text (ค่าเริ่มต้นเมื่อว่าง) หรือ json — เรียกตอนเริ่มโปรแกรมก่อนเขียน log
+func SetFormat(format string) error {
+	var selected encoder
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText:
+		selected = encodeText
+	case FormatJSON:
+		selected = encodeJSON
+	default:
+		return fmt.Errorf("unknown log format %q (want %s or %s)", format, FormatText, FormatJSON)
+	}
+	currentEncoder.Store(&selected)
+	return nil
+}
+
+func encode(line entry) []byte {
+	var buffer bytes.Buffer
+	(*currentEncoder.Load())(&buffer, line)
+	return buffer.Bytes()
+}
+
+// encodeText รูปแบบเดิม "YYYY-MM-DD HH:MM:SS.mmm [module] [level] <ctx> message <fields>"
+// ค่าที่มีช่องว่าง/เครื่องหมายคำพูด/= ถูกใส่ "..." แบบ Go quote ให้แยก field ได้แน่นอน
+func encodeText(buffer *bytes.Buffer, line entry) {
+	buffer.WriteString(line.time.Format("2006-01-02 15:04:05.000"))
+	buffer.WriteString(" [" + line.module + "] [" + line.level + "]")
+	writeTextFields(buffer, line.context)
+	if line.message != "" {
+		buffer.WriteString(" " + strings.ReplaceAll(line.message, "\n", " "))
+	}
+	writeTextFields(buffer, line.fields)
+	buffer.WriteByte('\n')
+}
+
+func writeTextFields(buffer *bytes.Buffer, fields []Field) {
+	for _, field := range fields {
+		if field.Key == "" {
+			continue
+		}
+		buffer.WriteString(" " + field.Key + "=" + textValue(field.Value))
+	}
+}
+
+func textValue(value any) string {
+	var text string
+	switch typed := value.(type) {
+	case string:
+		text = typed
+	case error:
+		text = typed.Error()
+	case fmt.Stringer:
+		text = typed.String()
+	case float64:
+		text = strconv.FormatFloat(typed, 'f', -1, 64)
+	case map[string]string:
+		// เช่น headers — เขียนเป็น JSON ให้อ่านกลับได้ (key เรียงตามตัวอักษร)
+		encoded, _ := json.Marshal(typed)
+		text = string(encoded)
+	default:
+		text = fmt.Sprint(value)
+	}
+	if text == "" || strings.ContainsAny(text, " \t\r\n\"=") {
+		return strconv.Quote(text)
+	}
+	return text
+}
+
+// encodeJSON หนึ่ง object ต่อบรรทัด — field หลัก time/level/module ขึ้นก่อน แล้วตามด้วยค่าจาก ctx, msg, field อื่น
+func encodeJSON(buffer *bytes.Buffer, line entry) {
+	buffer.WriteByte('{')
+	writeJSONField(buffer, "time", line.time.Format(time.RFC3339Nano), true)
+	writeJSONField(buffer, "level", line.level, false)
+	writeJSONField(buffer, "module", line.module, false)
+	for _, field := range line.context {
+		writeJSONField(buffer, field.Key, field.Value, false)
+	}
+	writeJSONField(buffer, "msg", line.message, false)
+	for _, field := range line.fields {
+		if field.Key == "" {
+			continue
+		}
+		writeJSONField(buffer, field.Key, field.Value, false)
+	}
+	buffer.WriteString("}\n")
+}
+
+func writeJSONField(buffer *bytes.Buffer, key string, value any, first bool) {
+	if !first {
+		buffer.WriteByte(',')
+	}
+	writeJSONValue(buffer, key)
+	buffer.WriteByte(':')
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	writeJSONValue(buffer, value)
+}
+
+// writeJSONValue เขียนค่าแบบไม่ escape <>& (body ของ request/response อ่านง่ายกว่า) — แปลงไม่ได้ใช้ fmt แทน
+func writeJSONValue(buffer *bytes.Buffer, value any) {
+	var encoded bytes.Buffer
+	jsonEncoder := json.NewEncoder(&encoded)
+	jsonEncoder.SetEscapeHTML(false)
+	if err := jsonEncoder.Encode(value); err != nil {
+		encoded.Reset()
+		_ = jsonEncoder.Encode(fmt.Sprint(value))
+	}
+	buffer.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+}
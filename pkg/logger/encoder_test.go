@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// goldenEntry บรรทัดเดียวที่มีทั้งค่าจาก ctx และ field ที่ต้อง quote/escape
+func goldenEntry() entry {
+	return entry{
+		time:    time.Date(2025, 8, 9, 5, 1, 14, 533000000, time.UTC),
+		module:  "books",
+		level:   LevelWarn.String(),
+		message: "title exists\nsecond line",
+		context: []Field{String("request_id", "req-1"), String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")},
+		fields: []Field{
+			Int("status", 409),
+			String("title", `Dune "Messiah"`),
+			String("query", "a=b"),
+			String("empty", ""),
+			{}, // Err(nil) — ไม่มี field
+			Err(errors.New("duplicate key")),
+			Milliseconds("latency_ms", 1234567*time.Nanosecond),
+			Bool("replayed", false),
+			Any("headers", map[string]string{"X-B": "2", "Content-Type": "application/json"}),
+			String("html", "<b>&</b>"),
+		},
+	}
+}
+
+func TestEncodeTextGolden(t *testing.T) {
+	var buffer bytes.Buffer
+	encodeText(&buffer, goldenEntry())
+	want := `2025-08-09 05:01:14.533 [books] [warn] request_id=req-1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 title exists second line` +
+		` status=409 title="Dune \"Messiah\"" query="a=b" empty="" error="duplicate key" latency_ms=1.234 replayed=false` +
+		` headers="{\"Content-Type\":\"application/json\",\"X-B\":\"2\"}" html=<b>&</b>` + "\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("text line\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestEncodeJSONGolden(t *testing.T) {
+	var buffer bytes.Buffer
+	encodeJSON(&buffer, goldenEntry())
+	want := `{"time":"2025-08-09T05:01:14.533Z","level":"warn","module":"books","request_id":"req-1","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"msg":"title exists\nsecond line","status":409,"title":"Dune \"Messiah\"","query":"a=b","empty":"","error":"duplicate key",` +
+		`"latency_ms":1.234,"replayed":false,"headers":{"Content-Type":"application/json","X-B":"2"},"html":"<b>&</b>"}` + "\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("json line\n got: %s\nwant: %s", got, want)
+	}
+
+	// ต้องเป็น JSON ที่ถูกต้องหนึ่ง object ต่อบรรทัด และมี key หลักครบ
+	line := buffer.Bytes()
+	if bytes.Count(line, []byte("\n")) != 1 {
+		t.Fatalf("json line has %d newlines, want exactly the trailing one", bytes.Count(line, []byte("\n")))
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("json line is not valid JSON: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, decoded["time"].(string)); err != nil {
+		t.Errorf("time %v: %v", decoded["time"], err)
+	}
+	if decoded["level"] != "warn" || decoded["msg"] != "title exists\nsecond line" {
+		t.Errorf("level = %v msg = %q", decoded["level"], decoded["msg"])
+	}
+}
+
+// ค่าที่ json แปลงไม่ได้ (เช่น channel) ไม่ทำให้บรรทัดเสีย — ใช้ข้อความจาก fmt แทน
+func TestEncodeJSONUnsupportedValue(t *testing.T) {
+	var buffer bytes.Buffer
+	encodeJSON(&buffer, entry{time: time.Unix(0, 0).UTC(), module: "api", level: "info", message: "m", fields: []Field{Any("ch", make(chan int))}})
+	var decoded map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatalf("line %s is not valid JSON: %v", buffer.String(), err)
+	}
+	if value, _ := decoded["ch"].(string); !strings.HasPrefix(value, "0x") {
+		t.Errorf("ch = %v, want the fmt representation", decoded["ch"])
+	}
+}
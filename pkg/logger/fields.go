@@ -0,0 +1,43 @@
+package logger
+
+import "time"
+
+// Field ค่าหนึ่งคู่ของ log แบบมีโครงสร้าง — text เขียนเป็น key=value, json เป็น property ของ object
+type Field struct {
+	Key   string
+	Value any
+}
+
+// String field ข้อความ
+func String(key, value string) Field { return Field{Key: key, Value: value} }
+
+// Int field จำนวนเต็ม
+func Int(key string, value int) Field { return Field{Key: key, Value: value} }
+
+// Int64 field จำนวนเต็ม 64 บิต
+func Int64(key string, value int64) Field { return Field{Key: key, Value: value} }
+
+// Uint field จำนวนเต็มไม่ติดลบ (เช่น id)
+func Uint(key string, value uint) Field { return Field{Key: key, Value: value} }
+
+// Float64 field ทศนิยม
+func Float64(key string, value float64) Field { return Field{Key: key, Value: value} }
+
+// Bool field true/false
+func Bool(key string, value bool) Field { return Field{Key: key, Value: value} }
+
+// Milliseconds field ระยะเวลาเป็นมิลลิวินาที (ทศนิยม 3 ตำแหน่ง) เช่น latency_ms
+func Milliseconds(key string, value time.Duration) Field {
+	return Field{Key: key, Value: float64(value.Microseconds()) / 1000}
+}
+
+// Err field "error" ของ err (nil = ไม่มี field นี้ในบรรทัด)
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+// Any field ค่าอะไรก็ได้ (json ใช้ encoding/json, text ใช้ fmt)
+func Any(key string, value any) Field { return Field{Key: key, Value: value} }
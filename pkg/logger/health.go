@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckWritable ตรวจว่าเขียนไฟล์ในโฟลเดอร์ log ได้ (สร้างไฟล์ชั่วคราวแล้วลบทิ้ง) ใช้กับ readiness
+func CheckWritable() error {
+	baseDir := logDir()
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return fmt.Errorf("create log dir: %w", err)
+	}
+	probe, err := os.CreateTemp(baseDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("log dir not writable: %w", err)
+	}
+	name := probe.Name()
+	_ = probe.Close()
+	return os.Remove(name)
+}
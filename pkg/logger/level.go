@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level ระดับของ log เรียงจากละเอียดสุดไปร้ายแรงสุด
+type Level int8
+
+const (
+	LevelTrace Level = iota - 2 // ละเอียดมาก (เช่น ค่าทุกขั้นตอน) — เปิดเฉพาะตอนไล่ปัญหา
+	LevelDebug                  // ข้อมูลช่วย debug
+	LevelInfo                   // เหตุการณ์ปกติ (ค่าเริ่มต้น)
+	LevelWarn                   // ผิดปกติแต่ทำงานต่อได้
+	LevelError                  // ล้มเหลว
+)
+
+func (level Level) String() string {
+	switch level {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int8(level))
+	}
+}
+
+// ParseLevel แปลงชื่อระดับ (trace|debug|info|warn|error ไม่สนตัวพิมพ์) เป็น Level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q (want trace, debug, info, warn or error)", name)
+	}
+}
+
+// Levels ระดับขั้นต่ำทั้งหมด (global + รายโมดูลที่ตั้งทับไว้)
+type Levels struct {
+	Global  Level
+	Modules map[string]Level
+}
+
+// levels ค่าปัจจุบันแบบ copy-on-write: write อ่านผ่าน atomic โดยไม่แตะ mutex ของไฟล์
+// (กรองบรรทัดที่ไม่ผ่านระดับทิ้งก่อนแปลงข้อความ) ส่วนการแก้ค่าถือ levelsMutex กันสองคนแก้ทับกัน
+var (
+	levels      atomic.Pointer[Levels]
+	levelsMutex sync.Mutex
+)
+
+func init() {
+	levels.Store(&Levels{Global: LevelInfo, Modules: map[string]Level{}})
+}
+
+// Enabled true ถ้าบรรทัดระดับ level ของ module จะถูกเขียน (ใช้ข้ามงานแพงๆ ก่อนเรียก Debug/Trace)
+func Enabled(module string, level Level) bool {
+	current := levels.Load()
+	minimum, ok := current.Modules[module]
+	if !ok {
+		minimum = current.Global
+	}
+	return level >= minimum
+}
+
+// CurrentLevels สำเนาของระดับปัจจุบัน (แก้ไขได้โดยไม่กระทบของจริง)
+func CurrentLevels() Levels {
+	current := levels.Load()
+	return Levels{Global: current.Global, Modules: maps.Clone(current.Modules)}
+}
+
+// SetLevels แทนที่ระดับทั้งหมดในครั้งเดียว
+func SetLevels(next Levels) {
+	modules := maps.Clone(next.Modules)
+	if modules == nil {
+		modules = map[string]Level{}
+	}
+	levelsMutex.Lock()
+	defer levelsMutex.Unlock()
+	levels.Store(&Levels{Global: next.Global, Modules: modules})
+}
+
+// UpdateLevels แก้ระดับบางส่วนแบบ atomic: global (nil = คงเดิม) และ module ที่ระบุ (ค่า nil = ลบการตั้งทับ กลับไปใช้ global)
+func UpdateLevels(global *Level, modules map[string]*Level) {
+	levelsMutex.Lock()
+	defer levelsMutex.Unlock()
+	current := levels.Load()
+	next := &Levels{Global: current.Global, Modules: maps.Clone(current.Modules)}
+	if global != nil {
+		next.Global = *global
+	}
+	for module, level := range modules {
+		if level == nil {
+			delete(next.Modules, module)
+		} else {
+			next.Modules[module] = *level
+		}
+	}
+	levels.Store(next)
+}
+
+// ParseLevels อ่านค่าแบบ config: global (เช่น "info") และรายโมดูล "books=warn,api=debug" (ว่างได้ทั้งคู่)
+func ParseLevels(global, modules string) (Levels, error) {
+	parsed := Levels{Global: LevelInfo, Modules: map[string]Level{}}
+	if strings.TrimSpace(global) != "" {
+		level, err := ParseLevel(global)
+		if err != nil {
+			return Levels{}, err
+		}
+		parsed.Global = level
+	}
+	for _, pair := range strings.Split(modules, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(pair, "=")
+		module = strings.TrimSpace(module)
+		if !ok || module == "" {
+			return Levels{}, fmt.Errorf("invalid module level %q (want module=level)", pair)
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return Levels{}, fmt.Errorf("module %s: %w", module, err)
+		}
+		parsed.Modules[module] = level
+	}
+	return parsed, nil
+}
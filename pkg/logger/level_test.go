@@ -0,0 +1,82 @@
+package logger_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// keepLevels คืนระดับเดิมเมื่อเทสต์จบ (ระดับเป็นค่ารวมของทั้ง process)
+func keepLevels(t *testing.T) {
+	t.Helper()
+	previous := logger.CurrentLevels()
+	t.Cleanup(func() { logger.SetLevels(previous) })
+}
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  string
+		modules string
+		want    logger.Levels
+		wantErr bool
+	}{
+		{"defaults", "", "", logger.Levels{Global: logger.LevelInfo, Modules: map[string]logger.Level{}}, false},
+		{"global only", "DEBUG", "", logger.Levels{Global: logger.LevelDebug, Modules: map[string]logger.Level{}}, false},
+		{"modules with spaces", "warn", " books = trace , api=error,", logger.Levels{Global: logger.LevelWarn, Modules: map[string]logger.Level{"books": logger.LevelTrace, "api": logger.LevelError}}, false},
+		{"warning alias", "", "cache=warning", logger.Levels{Global: logger.LevelInfo, Modules: map[string]logger.Level{"cache": logger.LevelWarn}}, false},
+		{"unknown global", "verbose", "", logger.Levels{}, true},
+		{"missing equals", "", "books", logger.Levels{}, true},
+		{"missing module", "", "=debug", logger.Levels{}, true},
+		{"unknown module level", "", "books=loud", logger.Levels{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := logger.ParseLevels(test.global, test.modules)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, test.wantErr)
+			}
+			if got.Global != test.want.Global || !maps.Equal(got.Modules, test.want.Modules) {
+				t.Errorf("ParseLevels(%q, %q) = %+v, want %+v", test.global, test.modules, got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateLevels(t *testing.T) {
+	keepLevels(t)
+	logger.SetLevels(logger.Levels{Global: logger.LevelInfo, Modules: map[string]logger.Level{"books": logger.LevelWarn, "cache": logger.LevelError}})
+
+	debug, trace := logger.LevelDebug, logger.LevelTrace
+	// global nil = คงเดิม, ค่า nil ของโมดูล = ลบการตั้งทับ
+	logger.UpdateLevels(nil, map[string]*logger.Level{"books": &debug, "cache": nil, "api": &trace})
+
+	want := logger.Levels{Global: logger.LevelInfo, Modules: map[string]logger.Level{"books": logger.LevelDebug, "api": logger.LevelTrace}}
+	if got := logger.CurrentLevels(); got.Global != want.Global || !maps.Equal(got.Modules, want.Modules) {
+		t.Fatalf("levels = %+v, want %+v", got, want)
+	}
+	checks := []struct {
+		module string
+		level  logger.Level
+		want   bool
+	}{
+		{"books", logger.LevelDebug, true},
+		{"books", logger.LevelTrace, false},
+		{"api", logger.LevelTrace, true},
+		{"cache", logger.LevelWarn, true}, // กลับไปใช้ global (info) → warn ผ่าน
+		{"cache", logger.LevelDebug, false},
+		{"other", logger.LevelInfo, true},
+	}
+	for _, check := range checks {
+		if got := logger.Enabled(check.module, check.level); got != check.want {
+			t.Errorf("Enabled(%s, %s) = %v, want %v", check.module, check.level, got, check.want)
+		}
+	}
+
+	// เปลี่ยน global ไม่กระทบโมดูลที่ตั้งทับไว้
+	logger.UpdateLevels(&trace, nil)
+	if !logger.Enabled("other", logger.LevelTrace) || logger.Enabled("books", logger.LevelTrace) {
+		t.Error("global trace should enable other modules but keep books at debug")
+	}
+}
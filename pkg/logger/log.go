@@ -1,91 +1,157 @@
 package logger
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 	"sync"
-	"time"
-)
 
-const (
-	baseDir        = "logs"            // โฟลเดอร์หลักเก็บ log
-	rotationWindow = 10 * time.Minute  // หมุนไฟล์ทุก 10 นาที
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
 )
 
+// ไฟล์ที่กำลังเขียนอยู่ — ทุกอย่างอ่าน/แก้ภายใต้ mutex (การหมุนไฟล์ดู rotate.go)
 var (
 	mutex       sync.Mutex
-	currentID   string   // ตัวระบุช่วงเวลา 10 นาที เช่น "2025-08-09_05-10"
+	currentID   string   // ช่วงเวลาของไฟล์ปัจจุบัน (ตาม RotationConfig.Window)
 	currentFile *os.File // ไฟล์ที่กำลังเขียนอยู่
+	currentPath string   // path ของ currentFile (งานเก็บกวาดข้ามไฟล์นี้)
+	currentSize int64    // ขนาดของ currentFile ใช้ตัดสินการหมุนตาม MaxSize
+	// currentWriter buffer หน้า currentFile: โหมดปกติ flush ทุกบรรทัด โหมด async flush ทีละชุด (ดู async.go)
+	currentWriter *bufio.Writer
 )
 
-// สร้าง path โฟลเดอร์/ไฟล์ จากเวลาปัจจุบัน
-// dir: logs/2025-08-09
-// file: logs/2025-08-09/log_2025-08-09_05-10.log
-func makePaths(now time.Time) (bucketID, dirPath, filePath string) {
-	trunc := now.Truncate(rotationWindow)
-	day := trunc.Format("2006-01-02")
-	min := trunc.Format("15-04")
-	bucketID = day + "_" + min
-
-	dirPath = filepath.Join(baseDir, day)
-	fileName := fmt.Sprintf("log_%s.log", bucketID)
-	filePath = filepath.Join(dirPath, fileName)
-	return
+// เขียนบรรทัด log ตามรูปแบบที่เลือกไว้ (ดู SetFormat) ไปยังทุก sink (ดู SetSinks) เช่น text:
+// "YYYY-MM-DD HH:MM:SS.mmm [module] [level] request_id=... trace_id=... span_id=... message key=value"
+func write(module string, level Level, context []Field, message string, fields []Field) {
+	record := newRecord(module, level, context, message, fields)
+	publish(record)
+	if enqueue(record) {
+		return
+	}
+	dispatch(record)
+	flushSinks()
 }
 
-// เปิดไฟล์ให้ตรงช่วง 10 นาทีปัจจุบัน (ถ้าเปลี่ยนช่วง จะปิดของเก่าแล้วเปิดของใหม่)
-func ensureFileLocked() error {
-	now := time.Now()
-	bucketID, dirPath, filePath := makePaths(now)
-
-	if currentFile != nil && bucketID == currentID {
-		return nil
-	}
-	if currentFile != nil {
-		_ = currentFile.Close()
-		currentFile = nil
-	}
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
+// writeLineLocked เขียนหนึ่งบรรทัดลง buffer ของไฟล์ปัจจุบัน (หมุนไฟล์ก่อนถ้าถึงเวลา/ขนาด)
+func writeLineLocked(line []byte) error {
+	if err := ensureFileLocked(len(line)); err != nil {
 		return err
 	}
-	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+	written, err := currentWriter.Write(line)
+	currentSize += int64(written)
+	return err
+}
+
+// flushLocked ส่งสิ่งที่ค้างใน buffer ลงไฟล์
+func flushLocked() {
+	if currentWriter != nil {
+		_ = currentWriter.Flush()
 	}
-	currentID = bucketID
-	currentFile = f
-	return nil
 }
 
-// เขียนบรรทัด log ตามรูปแบบ: "YYYY-MM-DD HH:MM:SS.mmm [module] [level] message"
-func write(module, level, message string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	line := fmt.Sprintf("%s [%s] [%s] %s\n", timestamp, module, strings.ToLower(level), message)
+// ปิดตอนโปรเซสจะจบ (เรียกจาก main: defer logger.Close())
+// เขียนบรรทัดที่ค้างในคิว async ให้หมดก่อน แล้วปิดทุก sink (ไฟล์รองานบีบอัด/ลบไฟล์เก่า, sink เครือข่ายส่งที่ค้างให้หมด)
+func Close() {
+	stopAsync()
+	closeSinks()
+}
 
-	mutex.Lock()
-	defer mutex.Unlock()
+// public helper (ใช้งานใน service/handlers)
+// บรรทัดที่ต่ำกว่าระดับของโมดูล (ดู SetLevels) ถูกทิ้งก่อน Sprintf จึงแทบไม่มีต้นทุน
+func Tracef(module, format string, a ...any) {
+	logf(context.Background(), module, LevelTrace, format, a)
+}
+func Debugf(module, format string, a ...any) {
+	logf(context.Background(), module, LevelDebug, format, a)
+}
+func Infof(module, format string, a ...any) {
+	logf(context.Background(), module, LevelInfo, format, a)
+}
+func Warnf(module, format string, a ...any) {
+	logf(context.Background(), module, LevelWarn, format, a)
+}
+func Errorf(module, format string, a ...any) {
+	logf(context.Background(), module, LevelError, format, a)
+}
 
-	if err := ensureFileLocked(); err != nil {
-		// ถ้าเปิดไฟล์ไม่ได้ พิมพ์ลงคอนโซลกันหาย
-		fmt.Printf("logger error: %v | %s", err, line)
+// แบบรับ ctx: ใส่ request ID และ trace/span ID จาก ctx ลงทุกบรรทัด (ใช้ในเส้นทางที่มาจาก HTTP request)
+func TracefContext(ctx context.Context, module, format string, a ...any) {
+	logf(ctx, module, LevelTrace, format, a)
+}
+func DebugfContext(ctx context.Context, module, format string, a ...any) {
+	logf(ctx, module, LevelDebug, format, a)
+}
+func InfofContext(ctx context.Context, module, format string, a ...any) {
+	logf(ctx, module, LevelInfo, format, a)
+}
+func WarnfContext(ctx context.Context, module, format string, a ...any) {
+	logf(ctx, module, LevelWarn, format, a)
+}
+func ErrorfContext(ctx context.Context, module, format string, a ...any) {
+	logf(ctx, module, LevelError, format, a)
+}
+
+// แบบมีโครงสร้าง: ข้อความคงที่ + field (json ได้เป็น property แยก ค้น/กรองได้ตรงๆ)
+//
+//	logger.Info("books", "created", logger.Uint("id", book.ID), logger.String("title", book.Title))
+func Trace(module, message string, fields ...Field) {
+	log(context.Background(), module, LevelTrace, message, fields)
+}
+func Debug(module, message string, fields ...Field) {
+	log(context.Background(), module, LevelDebug, message, fields)
+}
+func Info(module, message string, fields ...Field) {
+	log(context.Background(), module, LevelInfo, message, fields)
+}
+func Warn(module, message string, fields ...Field) {
+	log(context.Background(), module, LevelWarn, message, fields)
+}
+func Error(module, message string, fields ...Field) {
+	log(context.Background(), module, LevelError, message, fields)
+}
+
+func TraceContext(ctx context.Context, module, message string, fields ...Field) {
+	log(ctx, module, LevelTrace, message, fields)
+}
+func DebugContext(ctx context.Context, module, message string, fields ...Field) {
+	log(ctx, module, LevelDebug, message, fields)
+}
+func InfoContext(ctx context.Context, module, message string, fields ...Field) {
+	log(ctx, module, LevelInfo, message, fields)
+}
+func WarnContext(ctx context.Context, module, message string, fields ...Field) {
+	log(ctx, module, LevelWarn, message, fields)
+}
+func ErrorContext(ctx context.Context, module, message string, fields ...Field) {
+	log(ctx, module, LevelError, message, fields)
+}
+
+// logf / log ตรวจระดับก่อน แล้วจึงแปลงข้อความและดึงค่าจาก ctx
+func logf(ctx context.Context, module string, level Level, format string, a []any) {
+	if !Enabled(module, level) {
 		return
 	}
-	_, _ = currentFile.WriteString(line)
+	write(module, level, contextFields(ctx), fmt.Sprintf(format, a...), nil)
 }
 
-// ปิดไฟล์ตอนโปรเซสจะจบ (เรียกจาก main: defer logger.Close())
-func Close() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	if currentFile != nil {
-		_ = currentFile.Close()
-		currentFile = nil
+func log(ctx context.Context, module string, level Level, message string, fields []Field) {
+	if !Enabled(module, level) {
+		return
 	}
+	write(module, level, contextFields(ctx), message, fields)
 }
 
-// public helper (ใช้งานใน service/handlers)
-func Infof(module, format string, a ...any)  { write(module, "info", fmt.Sprintf(format, a...)) }
-func Warnf(module, format string, a ...any)  { write(module, "warn", fmt.Sprintf(format, a...)) }
-func Errorf(module, format string, a ...any) { write(module, "error", fmt.Sprintf(format, a...)) }
+// contextFields request_id, trace_id, span_id เฉพาะค่าที่มีใน ctx (trace ID ใช้ค้น trace ใน backend ของ OTel)
+func contextFields(ctx context.Context) []Field {
+	var fields []Field
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		fields = append(fields, String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, String("trace_id", spanContext.TraceID().String()), String("span_id", spanContext.SpanID().String()))
+	}
+	return fields
+}
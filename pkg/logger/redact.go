@@ -0,0 +1,363 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// redactedValue ค่าที่ใส่แทนข้อมูลที่ถูกปิด
+const redactedValue = "[REDACTED]"
+
+// RedactionConfig กติกาการปิดข้อมูลอ่อนไหวใน access log (ค่าเริ่มต้นดู RedactionFromEnv)
+type RedactionConfig struct {
+	// Fields ชื่อ field ใน JSON / form ที่ต้องปิดค่า (ไม่สนตัวพิมพ์)
+	//   "password"   ชื่อเดียว = ทุกระดับความลึก
+	//   "*.secret"   path จาก root คั่นด้วย "." — "*" แทนชื่อใดก็ได้หนึ่งระดับ (index ของ array ไม่นับเป็นระดับ)
+	Fields []string
+	// HeaderAllow header ที่เก็บลง log ("*" = ทุกตัว, ว่าง = ไม่เก็บ header เลย)
+	HeaderAllow []string
+	// HeaderDeny header ที่ปิดค่าเสมอ แม้อยู่ใน HeaderAllow
+	HeaderDeny []string
+	// Patterns regex ที่แทนด้วย [REDACTED] ทุกที่ใน body/header (เช่น อีเมล เลขบัตร)
+	Patterns []*regexp.Regexp
+	// SkipBodyRoutes route ที่ไม่เก็บ body เลย รูปแบบ "METHOD /path" หรือ "/path" (path เป็น route template ใช้ * ได้แบบ path.Match)
+	SkipBodyRoutes []string
+}
+
+// regex มาตรฐาน: อีเมล และเลขบัตร 13–19 หลัก (คั่นด้วยช่องว่าง/ขีดได้)
+// CardNumberPattern ปิดเฉพาะเลขที่ผ่าน Luhn — ISBN-13, timestamp หน่วย ms หรือ ID ยาวๆ ส่วนใหญ่จึงไม่ถูกปิด
+var (
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+)
+
+// RedactionFromEnv อ่านกติกาจาก env (คั่นด้วย ",")
+//
+//	LOG_REDACT_FIELDS      (password,passwd,token,access_token,refresh_token,secret,*.secret,api_key,authorization)
+//	LOG_HEADERS_ALLOW      (Content-Type,Content-Length,User-Agent,Accept,Accept-Language,Idempotency-Key,If-None-Match,If-Modified-Since)
+//	LOG_HEADERS_DENY       (Authorization,Proxy-Authorization,Cookie,Set-Cookie,X-Api-Key)
+//	LOG_REDACT_EMAILS      (true) / LOG_REDACT_CARDS (true)
+//	LOG_REDACT_REGEX       regex เพิ่มเติมหนึ่งตัว (รวมหลายแบบด้วย |)
+//	LOG_BODY_SKIP_ROUTES   เช่น "POST /api/v1/login,/admin/*"
+//
+// ถ้า LOG_REDACT_REGEX ผิด จะคืน error พร้อม config ที่เหลือ (ใช้ต่อได้โดยไม่มี regex ตัวนั้น)
+func RedactionFromEnv() (RedactionConfig, error) {
+	config := RedactionConfig{
+		Fields:         envList("LOG_REDACT_FIELDS", "password,passwd,token,access_token,refresh_token,secret,*.secret,api_key,authorization"),
+		HeaderAllow:    envList("LOG_HEADERS_ALLOW", "Content-Type,Content-Length,User-Agent,Accept,Accept-Language,Idempotency-Key,If-None-Match,If-Modified-Since"),
+		HeaderDeny:     envList("LOG_HEADERS_DENY", "Authorization,Proxy-Authorization,Cookie,Set-Cookie,X-Api-Key"),
+		SkipBodyRoutes: envList("LOG_BODY_SKIP_ROUTES", ""),
+	}
+	if os.Getenv("LOG_REDACT_EMAILS") != "false" {
+		config.Patterns = append(config.Patterns, EmailPattern)
+	}
+	if os.Getenv("LOG_REDACT_CARDS") != "false" {
+		config.Patterns = append(config.Patterns, CardNumberPattern)
+	}
+	if raw := os.Getenv("LOG_REDACT_REGEX"); raw != "" {
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			return config, fmt.Errorf("invalid LOG_REDACT_REGEX: %w", err)
+		}
+		config.Patterns = append(config.Patterns, pattern)
+	}
+	return config, nil
+}
+
+// envList อ่าน env แบบคั่นด้วย "," — ไม่ตั้งใช้ fallback, ตั้งเป็นค่าว่าง = รายการว่าง
+func envList(key, fallback string) []string {
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		raw = fallback
+	}
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// redactor กติกาที่แปลงแล้ว พร้อมใช้ใน AccessLog
+type redactor struct {
+	names     map[string]bool // ชื่อเดี่ยว (ตัวพิมพ์เล็ก) — ปิดทุกระดับ
+	paths     [][]string      // path แบบมี "." แยกเป็นส่วน (ตัวพิมพ์เล็ก)
+	allowAll  bool
+	allow     map[string]bool // canonical header name
+	deny      map[string]bool
+	patterns  []*regexp.Regexp
+	skipRoute []string
+	// keyValue ปิดค่าของชื่อเดี่ยวใน JSON ที่ parse ไม่ได้ (เช่น body ถูกตัดที่ maxLoggedBody)
+	keyValue *regexp.Regexp
+}
+
+var currentRedactor atomic.Pointer[redactor]
+
+func init() {
+	// ค่าเริ่มต้นก่อน ConfigureRedaction: ปิด field/header พื้นฐานไว้ก่อน (error ของ regex รายงานตอน main เรียกซ้ำ)
+	config, _ := RedactionFromEnv()
+	currentRedactor.Store(newRedactor(config))
+}
+
+// ConfigureRedaction ตั้งกติกาใหม่ (มีผลกับ request ถัดไปทันที)
+func ConfigureRedaction(config RedactionConfig) {
+	currentRedactor.Store(newRedactor(config))
+}
+
+func newRedactor(config RedactionConfig) *redactor {
+	redactor := &redactor{
+		names:     map[string]bool{},
+		allow:     map[string]bool{},
+		deny:      map[string]bool{},
+		patterns:  config.Patterns,
+		skipRoute: config.SkipBodyRoutes,
+	}
+	var quoted []string
+	for _, field := range config.Fields {
+		field = strings.ToLower(field)
+		if strings.Contains(field, ".") {
+			redactor.paths = append(redactor.paths, strings.Split(field, "."))
+			continue
+		}
+		redactor.names[field] = true
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	if len(quoted) > 0 {
+		redactor.keyValue = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	}
+	for _, header := range config.HeaderAllow {
+		if header == "*" {
+			redactor.allowAll = true
+			continue
+		}
+		redactor.allow[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, header := range config.HeaderDeny {
+		redactor.deny[http.CanonicalHeaderKey(header)] = true
+	}
+	return redactor
+}
+
+// skipBody true ถ้า route นี้ไม่เก็บ body ตาม SkipBodyRoutes
+func (redactor *redactor) skipBody(method, route string) bool {
+	for _, rule := range redactor.skipRoute {
+		ruleMethod, rulePath, hasMethod := strings.Cut(rule, " ")
+		if !hasMethod {
+			ruleMethod, rulePath = "", rule
+		}
+		if ruleMethod != "" && !strings.EqualFold(ruleMethod, method) {
+			continue
+		}
+		rulePath = strings.TrimSpace(rulePath)
+		if matched, _ := path.Match(rulePath, route); matched || rulePath == route {
+			return true
+		}
+		// "/admin/*" ครอบทุกระดับใต้ /admin
+		if prefix, ok := strings.CutSuffix(rulePath, "/*"); ok && strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// headers คืน header ที่อนุญาต (ค่าหลายตัวต่อด้วย ", ") — header ใน deny ถูกแทนด้วย [REDACTED]
+func (redactor *redactor) headers(header http.Header) map[string]string {
+	if !redactor.allowAll && len(redactor.allow) == 0 {
+		return nil
+	}
+	logged := map[string]string{}
+	for name, values := range header {
+		if !redactor.allowAll && !redactor.allow[name] {
+			continue
+		}
+		if redactor.deny[name] {
+			logged[name] = redactedValue
+			continue
+		}
+		logged[name] = redactor.scrub(strings.Join(values, ", "))
+	}
+	return logged
+}
+
+// body แปลง body ที่ดักไว้เป็นข้อความสำหรับ log: ปิด field ตามชนิดเนื้อหา แล้ว scrub ด้วย regex
+// multipart / binary ไม่เก็บเนื้อหา บอกแค่ชนิดและขนาด
+func (redactor *redactor) body(contentType string, raw []byte, truncated bool) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return fmt.Sprintf("[%s body omitted]", mediaType)
+	case !isTextual(mediaType) || !utf8.Valid(raw):
+		if mediaType == "" {
+			mediaType = "unknown"
+		}
+		return fmt.Sprintf("[binary body omitted type=%s]", mediaType)
+	}
+
+	var text string
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		text = redactor.form(string(raw))
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || (mediaType == "" && json.Valid(raw)):
+		text = redactor.json(raw)
+	default:
+		text = string(raw)
+	}
+	text = redactor.scrub(text)
+	if truncated {
+		text += "..."
+	}
+	return text
+}
+
+// isTextual ชนิดเนื้อหาที่เก็บเป็นข้อความได้ (ว่าง = ไม่บอกชนิด ให้ utf8.Valid ตัดสิน)
+func isTextual(mediaType string) bool {
+	switch {
+	case mediaType == "",
+		strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json", strings.HasSuffix(mediaType, "+json"),
+		mediaType == "application/xml", strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+// json ปิด field ตามชื่อ/path — parse ไม่ได้ (เช่น ถูกตัดกลาง) ใช้ regex ปิดเฉพาะชื่อเดี่ยวแทน
+// หมายเหตุ: JSON ที่ parse ได้จะถูกเขียนใหม่ ลำดับ key อาจเปลี่ยน
+func (redactor *redactor) json(raw []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		if redactor.keyValue == nil {
+			return string(raw)
+		}
+		return redactor.keyValue.ReplaceAllString(string(raw), `${1}"`+redactedValue+`"`)
+	}
+	redactor.walk(document, nil)
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return string(raw)
+	}
+	return strings.TrimSuffix(encoded.String(), "\n")
+}
+
+// walk เดินทุก object/array แล้วแทนค่าของ key ที่ตรงกติกา (parents = path ของ object ปัจจุบัน)
+func (redactor *redactor) walk(node any, parents []string) {
+	switch typed := node.(type) {
+	case map[string]any:
+		for key, value := range typed {
+			current := append(parents[:len(parents):len(parents)], strings.ToLower(key))
+			if redactor.matches(current) {
+				typed[key] = redactedValue
+				continue
+			}
+			redactor.walk(value, current)
+		}
+	case []any:
+		for _, value := range typed {
+			redactor.walk(value, parents)
+		}
+	}
+}
+
+func (redactor *redactor) matches(keyPath []string) bool {
+	if redactor.names[keyPath[len(keyPath)-1]] {
+		return true
+	}
+	for _, pattern := range redactor.paths {
+		if len(pattern) != len(keyPath) {
+			continue
+		}
+		matched := true
+		for index, segment := range pattern {
+			if segment != "*" && segment != keyPath[index] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// form ปิดค่าของ key ใน application/x-www-form-urlencoded ตามชื่อเดี่ยว แยกทีละคู่ตามลำดับเดิม
+// คู่ที่ decode ไม่ได้ (เช่น %zz หรือมี ;) ถูกปิดด้วย — ไม่รู้ชื่อ key แน่นอนจึงไม่เสี่ยงปล่อยค่าจริงออกไป
+// (key เสียทั้งคู่ถูกแทน เพราะค่าอาจอยู่ใน key เมื่อไม่มี =)
+func (redactor *redactor) form(raw string) string {
+	masked := url.QueryEscape(redactedValue)
+	pairs := strings.Split(raw, "&")
+	for index, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, keyErr := url.QueryUnescape(rawKey)
+		value, valueErr := url.QueryUnescape(rawValue)
+		switch {
+		case keyErr != nil || strings.Contains(rawKey, ";"):
+			pairs[index] = masked
+		case redactor.names[strings.ToLower(key)] || valueErr != nil || strings.Contains(rawValue, ";"):
+			pairs[index] = url.QueryEscape(key) + "=" + masked
+		default:
+			pairs[index] = url.QueryEscape(key) + "=" + url.QueryEscape(value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// scrub แทนทุกส่วนที่ตรง regex ด้วย [REDACTED] (CardNumberPattern แทนเฉพาะเลขที่ผ่าน Luhn)
+func (redactor *redactor) scrub(text string) string {
+	for _, pattern := range redactor.patterns {
+		if pattern == CardNumberPattern {
+			text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+				if !luhnValid(match) {
+					return match
+				}
+				return redactedValue
+			})
+			continue
+		}
+		text = pattern.ReplaceAllString(text, redactedValue)
+	}
+	return text
+}
+
+// luhnValid ตรวจ check digit แบบ Luhn (ข้ามช่องว่าง/ขีด)
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for index := len(number) - 1; index >= 0; index-- {
+		digit := number[index]
+		if digit < '0' || digit > '9' {
+			continue
+		}
+		value := int(digit - '0')
+		if double {
+			if value *= 2; value > 9 {
+				value -= 9
+			}
+		}
+		sum += value
+		double = !double
+	}
+	return sum%10 == 0
+}
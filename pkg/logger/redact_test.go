@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactFormFailsClosed(t *testing.T) {
+	redactor := newRedactor(RedactionConfig{Fields: []string{"password"}})
+	const masked = "%5BREDACTED%5D"
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"valid", "user=alice&password=hunter2", "user=alice&password=" + masked},
+		{"case insensitive key", "PassWord=hunter2", "PassWord=" + masked},
+		{"bad escape elsewhere", "password=hunter2&x=%zz", "password=" + masked + "&x=" + masked},
+		{"bad escape in secret", "password=hun%zzter2&user=alice", "password=" + masked + "&user=alice"},
+		{"bad escape in key", "pass%zzword=hunter2&user=alice", masked + "&user=alice"},
+		{"semicolon", "user=alice;password=hunter2", "user=" + masked},
+		{"truncated", "user=alice&password=hunter%2", "user=alice&password=" + masked},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := redactor.body("application/x-www-form-urlencoded", []byte(tc.body), false)
+			if got != tc.want {
+				t.Errorf("body(%q) = %q, want %q", tc.body, got, tc.want)
+			}
+			if strings.Contains(got, "hunter") {
+				t.Errorf("body(%q) leaked the password: %q", tc.body, got)
+			}
+		})
+	}
+}
+
+// เลขยาวที่ไม่ผ่าน Luhn (ISBN, timestamp หน่วย ms) ต้องไม่ถูกปิด
+func TestRedactCardNumbers(t *testing.T) {
+	redactor := newRedactor(RedactionConfig{Patterns: []*regexp.Regexp{CardNumberPattern}})
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"card", "card=4111111111111111", "card=" + redactedValue},
+		{"card with spaces", "pay 4111 1111 1111 1111 now", "pay " + redactedValue + " now"},
+		{"card with dashes", "5500-0000-0000-0004", redactedValue},
+		{"isbn-13", "isbn=978-0-306-40615-7", "isbn=978-0-306-40615-7"},
+		{"unix ms timestamp", "at=1760845926123", "at=1760845926123"},
+		{"card next to isbn", "4111111111111111 9780451524935", redactedValue + " 9780451524935"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := redactor.scrub(tc.text); got != tc.want {
+				t.Errorf("scrub(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationConfig การหมุนไฟล์และการเก็บรักษา log (ค่าเริ่มต้นดู RotationFromEnv)
+type RotationConfig struct {
+	Dir          string        // โฟลเดอร์หลัก ไฟล์อยู่ที่ <Dir>/YYYY-MM-DD/log_YYYY-MM-DD_HH-mm[.N].log
+	Window       time.Duration // หมุนไฟล์ตามช่วงเวลา (0 = ไม่หมุนตามเวลา ชื่อไฟล์ใช้เวลาที่เปิด)
+	MaxSize      int64         // หมุนเมื่อไฟล์จะเกินขนาดนี้ (ไบต์, 0 = ไม่จำกัด) ไฟล์ถัดไปในช่วงเดียวกันต่อท้าย .1, .2, ...
+	Compress     bool          // gzip ไฟล์ที่ปิดแล้วเบื้องหลัง (.log → .log.gz)
+	MaxAge       time.Duration // ลบไฟล์ที่แก้ไขล่าสุดเก่ากว่านี้ (0 = ไม่ลบตามอายุ)
+	MaxTotalSize int64         // ลบไฟล์เก่าสุดจนขนาดรวมใน Dir ไม่เกินนี้ (ไบต์, 0 = ไม่จำกัด)
+	LatestLink   bool          // symlink <Dir>/latest.log ชี้ไฟล์ที่กำลังเขียน
+}
+
+// latestLinkName ชื่อ symlink ของไฟล์ปัจจุบันใน Dir
+const latestLinkName = "latest.log"
+
+// rotation ค่าที่ใช้อยู่ — อ่าน/แก้ภายใต้ mutex เดียวกับไฟล์
+// ค่าเริ่มต้นไม่บีบอัดและไม่ลบไฟล์เก่า (เหมือนก่อนมี LOG_COMPRESS/LOG_MAX_*) — ต้องเปิดเองผ่าน env
+var rotation = RotationConfig{
+	Dir:        "logs",
+	Window:     10 * time.Minute,
+	MaxSize:    100 << 20,
+	LatestLink: true,
+}
+
+// RotationFromEnv อ่านค่าจาก env (ไม่ตั้งหรือผิดรูปแบบใช้ค่าเริ่มต้น)
+//
+//	LOG_DIR (logs), LOG_ROTATE_INTERVAL (10m), LOG_ROTATE_MAX_SIZE (100MB)
+//	LOG_COMPRESS (false), LOG_MAX_AGE (0), LOG_MAX_TOTAL_SIZE (0), LOG_LATEST_LINK (true)
+//
+// ขนาดเขียนเป็นไบต์หรือมีหน่วย KB/MB/GB (ฐาน 1024) และ 0 = ไม่จำกัด
+func RotationFromEnv() RotationConfig {
+	config := rotation
+	if dir := strings.TrimSpace(os.Getenv("LOG_DIR")); dir != "" {
+		config.Dir = dir
+	}
+	config.Window = envDuration("LOG_ROTATE_INTERVAL", config.Window)
+	config.MaxSize = envSize("LOG_ROTATE_MAX_SIZE", config.MaxSize)
+	config.Compress = envBool("LOG_COMPRESS", config.Compress)
+	config.MaxAge = envDuration("LOG_MAX_AGE", config.MaxAge)
+	config.MaxTotalSize = envSize("LOG_MAX_TOTAL_SIZE", config.MaxTotalSize)
+	config.LatestLink = envBool("LOG_LATEST_LINK", config.LatestLink)
+	return config
+}
+
+// SetRotation เปลี่ยนกติกา: ปิดไฟล์ปัจจุบัน บรรทัดถัดไปจะเปิดไฟล์ตามค่าใหม่ แล้วเก็บกวาดไฟล์เก่าเบื้องหลัง
+func SetRotation(config RotationConfig) {
+	if config.Dir == "" {
+		config.Dir = "logs"
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	closeFileLocked()
+	rotation = config
+	scheduleHousekeeping()
+}
+
+// logDir โฟลเดอร์หลักที่ใช้อยู่
+func logDir() string {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return rotation.Dir
+}
+
+// สร้าง path โฟลเดอร์/ไฟล์ของช่วงเวลา (ลำดับ index = 0 ไม่มีเลขต่อท้าย)
+// dir: logs/2025-08-09
+// file: logs/2025-08-09/log_2025-08-09_05-10.log, log_2025-08-09_05-10.1.log, ...
+func makePaths(start time.Time, index int) (dirPath, filePath string) {
+	day := start.Format("2006-01-02")
+	name := "log_" + start.Format("2006-01-02_15-04")
+	if index > 0 {
+		name += "." + strconv.Itoa(index)
+	}
+	dirPath = filepath.Join(rotation.Dir, day)
+	filePath = filepath.Join(dirPath, name+".log")
+	return
+}
+
+// ensureFileLocked เปิดไฟล์ให้พร้อมเขียน incoming ไบต์: เปลี่ยนช่วงเวลาหรือไฟล์จะเกิน MaxSize → ปิดของเก่าแล้วเปิดไฟล์ใหม่
+func ensureFileLocked(incoming int) error {
+	now := time.Now()
+	bucketID := currentID
+	if rotation.Window > 0 {
+		bucketID = now.Truncate(rotation.Window).Format(time.RFC3339)
+	}
+	if currentFile != nil && bucketID == currentID {
+		if rotation.MaxSize <= 0 || currentSize == 0 || currentSize+int64(incoming) <= rotation.MaxSize {
+			return nil
+		}
+	}
+
+	start := windowStartLocked(now)
+	rotated := currentFile != nil
+	previous := currentPath
+	// ต่อไฟล์ล่าสุดของช่วงถ้ายังไม่เต็ม (เช่น เปิดโปรแกรมใหม่ในช่วงเดิม) ไม่งั้นใช้ index ถัดไป
+	// ไฟล์ที่เพิ่งปิดเพราะขนาดไม่นำกลับมาใช้ แม้ยังไม่ถึง MaxSize พอดี
+	index := 0
+	var size int64
+	if last, ok := lastIndex(start); ok {
+		_, lastPath := makePaths(start, last)
+		info, err := os.Stat(lastPath)
+		if err == nil && lastPath != previous && (rotation.MaxSize <= 0 || info.Size() < rotation.MaxSize) {
+			index, size = last, info.Size()
+		} else {
+			index = last + 1
+		}
+	}
+	closeFileLocked()
+
+	dirPath, filePath := makePaths(start, index)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if rotation.Window > 0 {
+		currentID = bucketID
+	} else {
+		currentID = "open"
+	}
+	currentFile = f
+	currentWriter = bufio.NewWriterSize(f, 64<<10)
+	currentPath = filePath
+	currentSize = size
+	if rotation.LatestLink {
+		updateLatestLink(filePath)
+	}
+	// ครั้งแรกหลังเปิดโปรแกรมก็เก็บกวาดด้วย (ไฟล์ค้างจากรอบก่อน)
+	if rotated || !housekeptOnce {
+		housekeptOnce = true
+		scheduleHousekeeping()
+	}
+	return nil
+}
+
+// windowStartLocked เวลาเริ่มของช่วงที่ now อยู่ (ไม่หมุนตามเวลา = now) ใช้ตั้งชื่อไฟล์
+func windowStartLocked(now time.Time) time.Time {
+	if rotation.Window > 0 {
+		return now.Truncate(rotation.Window)
+	}
+	return now
+}
+
+// reusablePathLocked ไฟล์ล่าสุดของช่วงปัจจุบัน ("" = ยังไม่มี) — ensureFileLocked อาจเปิดไฟล์นี้ต่อ
+func reusablePathLocked(now time.Time) string {
+	start := windowStartLocked(now)
+	last, ok := lastIndex(start)
+	if !ok {
+		return ""
+	}
+	_, path := makePaths(start, last)
+	return path
+}
+
+// closeFileLocked ปิดไฟล์ปัจจุบัน (ถ้ามี) — ไฟล์ที่ปิดแล้วจะถูกบีบอัดรอบเก็บกวาดถัดไป
+func closeFileLocked() {
+	if currentFile != nil {
+		_ = currentWriter.Flush()
+		_ = currentFile.Close()
+	}
+	currentFile = nil
+	currentWriter = nil
+	currentID = ""
+	currentPath = ""
+	currentSize = 0
+}
+
+// lastIndex index สูงสุดของไฟล์ในช่วง start ที่มีอยู่แล้ว (ทั้ง .log และ .log.gz)
+func lastIndex(start time.Time) (int, bool) {
+	dirPath, basePath := makePaths(start, 0)
+	prefix := strings.TrimSuffix(filepath.Base(basePath), ".log")
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return 0, false
+	}
+	last, found := 0, false
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSuffix(strings.TrimSuffix(rest, ".gz"), ".log")
+		index := 0
+		if rest != "" {
+			number, err := strconv.Atoi(strings.TrimPrefix(rest, "."))
+			if err != nil || !strings.HasPrefix(rest, ".") {
+				continue
+			}
+			index = number
+		}
+		if !found || index > last {
+			last, found = index, true
+		}
+	}
+	return last, found
+}
+
+// updateLatestLink ชี้ latest.log ไปยัง filePath แบบ relative (สร้าง link ชั่วคราวแล้ว rename ทับ จึงไม่มีช่วงที่ link หาย)
+// ระบบที่สร้าง symlink ไม่ได้ (เช่น Windows ที่ไม่มีสิทธิ์) ข้ามไปเฉยๆ
+func updateLatestLink(filePath string) {
+	link := filepath.Join(rotation.Dir, latestLinkName)
+	target, err := filepath.Rel(rotation.Dir, filePath)
+	if err != nil {
+		return
+	}
+	temporary := link + ".tmp"
+	_ = os.Remove(temporary)
+	if err := os.Symlink(target, temporary); err != nil {
+		return
+	}
+	if err := os.Rename(temporary, link); err != nil {
+		_ = os.Remove(temporary)
+	}
+}
+
+// housekeeping สถานะของงานเก็บกวาดเบื้องหลัง: มีได้ทีละรอบ ขอซ้ำระหว่างทำจะวนอีกรอบเมื่อจบ
+var (
+	housekeepingMutex   sync.Mutex
+	housekeepingRunning bool
+	housekeepingPending bool
+	housekeepingDone    sync.WaitGroup
+	housekeptOnce       bool // เก็บกวาดครั้งแรกหลังเปิดไฟล์แล้วหรือยัง (ใช้ภายใต้ mutex)
+)
+
+// scheduleHousekeeping สั่งบีบอัด + ลบไฟล์เก่าเบื้องหลัง (ไม่บล็อกคนเขียน log)
+func scheduleHousekeeping() {
+	housekeepingMutex.Lock()
+	defer housekeepingMutex.Unlock()
+	if housekeepingRunning {
+		housekeepingPending = true
+		return
+	}
+	housekeepingRunning = true
+	housekeepingDone.Add(1)
+	go func() {
+		defer housekeepingDone.Done()
+		for {
+			runHousekeeping()
+			housekeepingMutex.Lock()
+			if !housekeepingPending {
+				housekeepingRunning = false
+				housekeepingMutex.Unlock()
+				return
+			}
+			housekeepingPending = false
+			housekeepingMutex.Unlock()
+		}
+	}()
+}
+
+// waitHousekeeping รอรอบเก็บกวาดที่ค้างอยู่ให้จบ (Close เรียก กันไฟล์ .gz ครึ่งๆ กลางๆ)
+func waitHousekeeping() {
+	housekeepingDone.Wait()
+}
+
+// logFile ไฟล์ log หนึ่งไฟล์ใน Dir (.log หรือ .log.gz)
+type logFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// runHousekeeping บีบอัดไฟล์ที่ปิดแล้ว จากนั้นลบตามอายุและขนาดรวม — ไม่แตะไฟล์ที่กำลังเขียน
+func runHousekeeping() {
+	// รายชื่อไฟล์ต้องได้ก่อนอ่าน currentPath: ไฟล์ที่เปิดใหม่หลังจากนี้จะไม่อยู่ในรายการ
+	mutex.Lock()
+	config := rotation
+	mutex.Unlock()
+	files := listLogFiles(config.Dir)
+
+	mutex.Lock()
+	active := currentPath
+	reusable := reusablePathLocked(time.Now())
+	mutex.Unlock()
+	// ไม่แตะไฟล์ที่กำลังเขียน และไฟล์ล่าสุดของช่วงปัจจุบันที่ ensureFileLocked จะเปิดต่อ
+	// (ตอนเริ่มโปรแกรม/หลัง SetRotation currentPath ยังว่าง ถ้าบีบอัดหรือลบไฟล์นั้นไป บรรทัดที่เขียนต่อจะหาย)
+	inUse := func(path string) bool { return path == active || path == reusable }
+
+	if config.Compress {
+		for index, file := range files {
+			if inUse(file.path) || !strings.HasSuffix(file.path, ".log") {
+				continue
+			}
+			if compressed, err := compressFile(file.path); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: compress %s: %v\n", file.path, err)
+			} else {
+				files[index] = compressed
+			}
+		}
+	}
+
+	// เก่าสุดก่อน
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.Before(files[j].modTime)
+		}
+		return files[i].path < files[j].path
+	})
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	cutoff := time.Now().Add(-config.MaxAge)
+	for _, file := range files {
+		if inUse(file.path) {
+			continue
+		}
+		expired := config.MaxAge > 0 && file.modTime.Before(cutoff)
+		oversize := config.MaxTotalSize > 0 && total > config.MaxTotalSize
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "logger: remove %s: %v\n", file.path, err)
+			continue
+		}
+		total -= file.size
+		// ลบโฟลเดอร์วันที่ว่างแล้ว (ไม่ว่างจะ error เฉยๆ)
+		if dir := filepath.Dir(file.path); filepath.Clean(dir) != filepath.Clean(config.Dir) {
+			_ = os.Remove(dir)
+		}
+	}
+}
+
+// listLogFiles ไฟล์ log_*.log / log_*.log.gz ทั้งหมดใต้ dir (ไม่รวม symlink และไฟล์ชั่วคราว)
+func listLogFiles(dir string) []logFile {
+	var files []logFile
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		name := entry.Name()
+		if !strings.HasPrefix(name, "log_") || !(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, logFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	return files
+}
+
+// compressFile gzip path เป็น path.gz (เขียนไฟล์ชั่วคราวแล้ว rename) คงเวลาแก้ไขเดิมไว้ให้นับอายุถูก แล้วลบต้นฉบับ
+func compressFile(path string) (logFile, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return logFile{}, err
+	}
+	defer source.Close()
+	info, err := source.Stat()
+	if err != nil {
+		return logFile{}, err
+	}
+
+	temporary := path + ".gz.tmp"
+	target, err := os.OpenFile(temporary, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return logFile{}, err
+	}
+	writer := gzip.NewWriter(target)
+	_, err = io.Copy(writer, source)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(temporary, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(temporary, path+".gz")
+	}
+	if err != nil {
+		_ = os.Remove(temporary)
+		return logFile{}, err
+	}
+	_ = os.Remove(path)
+
+	compressed, err := os.Stat(path + ".gz")
+	if err != nil {
+		return logFile{}, err
+	}
+	return logFile{path: path + ".gz", size: compressed.Size(), modTime: info.ModTime()}, nil
+}
+
+// envDuration อ่าน env แบบ time.ParseDuration — ค่าติดลบหรือผิดรูปแบบใช้ fallback
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		fmt.Fprintf(os.Stderr, "logger: invalid %s=%q, using %s\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// envBool อ่าน env แบบ strconv.ParseBool — ผิดรูปแบบใช้ fallback
+func envBool(key string, fallback bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: invalid %s=%q, using %t\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// envSize อ่านขนาดเช่น "100MB", "512KB", "1GB" หรือจำนวนไบต์ — ผิดรูปแบบใช้ fallback
+func envSize(key string, fallback int64) int64 {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	value, err := parseSize(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: invalid %s=%q, using %d bytes\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func parseSize(raw string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(raw))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(upper, unit.suffix); ok {
+			upper, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	value, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return value * multiplier, nil
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useRotation ตั้ง rotation ตรงๆ (ไม่สั่งเก็บกวาดเบื้องหลังแบบ SetRotation) แล้วคืนค่าเดิมเมื่อเทสต์จบ
+func useRotation(t *testing.T, config RotationConfig) {
+	t.Helper()
+	mutex.Lock()
+	previous := rotation
+	closeFileLocked()
+	rotation = config
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		closeFileLocked()
+		rotation = previous
+		mutex.Unlock()
+	})
+}
+
+func writeLogFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// เปิดโปรแกรมใหม่ในช่วงเดิม: เก็บกวาดตอนเริ่ม (currentPath ยังว่าง) ต้องไม่บีบอัด/ลบไฟล์ที่ ensureFileLocked จะเขียนต่อ
+func TestHousekeepingSkipsFileReusedAfterRestart(t *testing.T) {
+	useRotation(t, RotationConfig{Dir: t.TempDir(), Window: time.Hour, Compress: true, MaxAge: time.Minute})
+	now := time.Now()
+	start := now.Truncate(time.Hour)
+	_, reusable := makePaths(start, 0)
+	_, previous := makePaths(start.Add(-time.Hour), 0)
+	// ทั้งสองไฟล์เก่ากว่า MaxAge — ไฟล์ของช่วงก่อนต้องถูกบีบอัดแล้วลบ ไฟล์ของช่วงปัจจุบันต้องอยู่
+	writeLogFile(t, reusable, "before restart\n", now.Add(-2*time.Minute))
+	writeLogFile(t, previous, "previous window\n", now.Add(-2*time.Minute))
+
+	runHousekeeping()
+
+	for _, path := range []string{previous, previous + ".gz"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists (err %v), want compressed then removed", path, err)
+		}
+	}
+	if _, err := os.Stat(reusable + ".gz"); !os.IsNotExist(err) {
+		t.Fatalf("reusable file was compressed")
+	}
+
+	// บรรทัดหลังเปิดโปรแกรมใหม่ต่อท้ายไฟล์เดิม
+	mutex.Lock()
+	err := ensureFileLocked(len("after restart\n"))
+	if err == nil {
+		_, err = currentWriter.WriteString("after restart\n")
+	}
+	if err == nil {
+		err = currentWriter.Flush()
+	}
+	path := currentPath
+	mutex.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != reusable {
+		t.Fatalf("opened %s, want %s", path, reusable)
+	}
+	content, err := os.ReadFile(reusable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "before restart\nafter restart\n" {
+		t.Errorf("content = %q", content)
+	}
+	waitHousekeeping()
+}
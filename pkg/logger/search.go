@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLine หนึ่งบรรทัดที่แยกส่วนหัวแล้ว (ผลของ Search และ tail) — Line คือข้อความเต็มตามที่เขียนลงไฟล์
+type LogLine struct {
+	Time   time.Time `json:"time"`
+	Level  string    `json:"level"`
+	Module string    `json:"module"`
+	Line   string    `json:"line"`
+}
+
+// LogLine ของ record (ไม่มี \n ท้าย)
+func (record Record) LogLine() LogLine {
+	return LogLine{
+		Time:   record.Time,
+		Level:  record.Level.String(),
+		Module: record.Module,
+		Line:   strings.TrimSuffix(string(record.Line), "\n"),
+	}
+}
+
+// Filter เงื่อนไขร่วมของการค้นและ tail
+type Filter struct {
+	Level  Level  // ระดับขั้นต่ำ (เช่น warn = warn + error)
+	Module string // ว่าง = ทุกโมดูล
+	Text   string // ข้อความที่ต้องมีในบรรทัด ไม่สนตัวพิมพ์ (ว่าง = ไม่กรอง)
+}
+
+// Matches true ถ้าบรรทัดผ่านทุกเงื่อนไข
+func (filter Filter) Matches(line LogLine) bool {
+	level, err := ParseLevel(line.Level)
+	if err != nil || level < filter.Level {
+		return false
+	}
+	if filter.Module != "" && line.Module != filter.Module {
+		return false
+	}
+	return filter.Text == "" || strings.Contains(strings.ToLower(line.Line), strings.ToLower(filter.Text))
+}
+
+// SearchQuery ช่วงเวลา [From, To] + Filter และจำนวนผลสูงสุด
+type SearchQuery struct {
+	Filter
+	From  time.Time
+	To    time.Time
+	Limit int // 0 = ไม่จำกัด
+}
+
+// Search ไล่อ่านไฟล์ที่หมุนแล้วใน LOG_DIR ตามลำดับเวลา (รวม .log.gz) แล้วเรียก emit ทีละบรรทัดที่ตรงเงื่อนไข
+// หยุดเมื่อครบ Limit, emit คืน error หรือ ctx ถูกยกเลิก — คืนจำนวนบรรทัดที่ส่งไป
+// บรรทัดที่ยังค้างในคิว async ถูกเขียนลงไฟล์ก่อนเริ่ม (Flush)
+func Search(ctx context.Context, query SearchQuery, emit func(LogLine) error) (int, error) {
+	Flush()
+	files := searchFiles(logDir(), query.From, query.To)
+
+	matched := 0
+	for _, file := range files {
+		done, err := searchFile(ctx, file.path, query, func(line LogLine) error {
+			matched++
+			if err := emit(line); err != nil {
+				return err
+			}
+			if query.Limit > 0 && matched >= query.Limit {
+				return errSearchLimit
+			}
+			return nil
+		})
+		if errors.Is(err, errSearchLimit) || done {
+			return matched, nil
+		}
+		if err != nil {
+			return matched, err
+		}
+	}
+	return matched, nil
+}
+
+// errSearchLimit ใช้หยุดการอ่านเมื่อครบ Limit (ไม่ใช่ error จริง)
+var errSearchLimit = errors.New("search limit reached")
+
+// searchCandidate ไฟล์ log หนึ่งไฟล์พร้อมเวลาเริ่มจากชื่อไฟล์ (ใช้เรียงลำดับ)
+type searchCandidate struct {
+	path  string
+	start time.Time
+	index int
+}
+
+// searchFiles ไฟล์ที่อาจมีบรรทัดในช่วง: เริ่มก่อน to และแก้ไขล่าสุดหลัง from — เรียงตามเวลาเริ่มและลำดับ .N
+func searchFiles(dir string, from, to time.Time) []searchCandidate {
+	var files []searchCandidate
+	for _, file := range listLogFiles(dir) {
+		start, index, ok := parseLogFileName(filepath.Base(file.path))
+		if !ok {
+			continue
+		}
+		if !to.IsZero() && start.After(to) {
+			continue
+		}
+		if !from.IsZero() && file.modTime.Before(from) {
+			continue
+		}
+		files = append(files, searchCandidate{path: file.path, start: start, index: index})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].start.Equal(files[j].start) {
+			return files[i].start.Before(files[j].start)
+		}
+		return files[i].index < files[j].index
+	})
+	return files
+}
+
+// parseLogFileName log_2025-08-09_05-10[.N].log[.gz] → เวลาเริ่ม (เวลาท้องถิ่นตามที่ makePaths ตั้ง) และ N
+func parseLogFileName(name string) (time.Time, int, bool) {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".log")
+	name, ok := strings.CutPrefix(name, "log_")
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	stamp, suffix, hasIndex := strings.Cut(name, ".")
+	start, err := time.ParseInLocation("2006-01-02_15-04", stamp, time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	index := 0
+	if hasIndex {
+		if index, err = strconv.Atoi(suffix); err != nil {
+			return time.Time{}, 0, false
+		}
+	}
+	return start, index, true
+}
+
+// searchFile อ่านหนึ่งไฟล์ — done = true เมื่อเจอบรรทัดที่เลย To แล้ว (ไฟล์ถัดไปใหม่กว่าทั้งหมด)
+func searchFile(ctx context.Context, path string, query SearchQuery, emit func(LogLine) error) (done bool, err error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && strings.HasSuffix(path, ".log") {
+		// ถูกบีบอัดระหว่างที่ค้นอยู่
+		path += ".gz"
+		file, err = os.Open(path)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		// ถูกลบตาม retention ระหว่างที่ค้นอยู่
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	var source io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		decompressed, err := gzip.NewReader(file)
+		if err != nil {
+			return false, err
+		}
+		defer decompressed.Close()
+		source = decompressed
+	}
+
+	reader := bufio.NewReaderSize(source, 64<<10)
+	for count := 0; ; count++ {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+		}
+		text, readErr := reader.ReadString('\n')
+		if text != "" {
+			line, ok := parseLogLine(strings.TrimSuffix(text, "\n"))
+			switch {
+			case !ok:
+			case !query.To.IsZero() && line.Time.After(query.To):
+				return true, nil
+			case !query.From.IsZero() && line.Time.Before(query.From):
+			case query.Matches(line):
+				if err := emit(line); err != nil {
+					return false, err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			return false, nil
+		}
+		if readErr != nil {
+			return false, readErr
+		}
+	}
+}
+
+// parseLogLine แยกส่วนหัวของบรรทัดได้ทั้งสองรูปแบบ (ไฟล์เก่าอาจเขียนด้วย LOG_FORMAT คนละแบบ)
+//
+//	text: 2025-08-09 05:01:14.533 [books] [info] ...
+//	json: {"time":"...","level":"info","module":"books",...}
+func parseLogLine(text string) (LogLine, bool) {
+	if strings.HasPrefix(text, "{") {
+		var header struct {
+			Time   time.Time `json:"time"`
+			Level  string    `json:"level"`
+			Module string    `json:"module"`
+		}
+		if err := json.Unmarshal([]byte(text), &header); err != nil {
+			return LogLine{}, false
+		}
+		return LogLine{Time: header.Time, Level: header.Level, Module: header.Module, Line: text}, true
+	}
+
+	const stampLayout = "2006-01-02 15:04:05.000"
+	if len(text) < len(stampLayout) {
+		return LogLine{}, false
+	}
+	stamp, err := time.ParseInLocation(stampLayout, text[:len(stampLayout)], time.Local)
+	if err != nil {
+		return LogLine{}, false
+	}
+	rest, ok := strings.CutPrefix(text[len(stampLayout):], " [")
+	if !ok {
+		return LogLine{}, false
+	}
+	module, rest, ok := strings.Cut(rest, "] [")
+	if !ok {
+		return LogLine{}, false
+	}
+	level, _, ok := strings.Cut(rest, "]")
+	if !ok {
+		return LogLine{}, false
+	}
+	return LogLine{Time: stamp, Level: level, Module: module, Line: text}, true
+}
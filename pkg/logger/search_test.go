@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want LogLine
+		ok   bool
+	}{
+		{
+			"text",
+			"2025-08-09 05:01:14.533 [books] [warn] request_id=req-1 title exists status=409",
+			LogLine{Time: time.Date(2025, 8, 9, 5, 1, 14, 533000000, time.Local), Level: "warn", Module: "books"},
+			true,
+		},
+		{
+			"json",
+			`{"time":"2025-08-09T05:01:14.533Z","level":"error","module":"api","msg":"boom"}`,
+			LogLine{Time: time.Date(2025, 8, 9, 5, 1, 14, 533000000, time.UTC), Level: "error", Module: "api"},
+			true,
+		},
+		{"too short", "2025-08-09", LogLine{}, false},
+		{"bad time", "2025-13-09 05:01:14.533 [books] [warn] x", LogLine{}, false},
+		{"missing level", "2025-08-09 05:01:14.533 [books] warn", LogLine{}, false},
+		{"plain text", "panic: runtime error: index out of range", LogLine{}, false},
+		{"broken json", `{"time":"2025-08-09T05:01:14Z","level":`, LogLine{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseLogLine(test.text)
+			if ok != test.ok {
+				t.Fatalf("ok = %v, want %v", ok, test.ok)
+			}
+			if !ok {
+				return
+			}
+			if !got.Time.Equal(test.want.Time) || got.Level != test.want.Level || got.Module != test.want.Module || got.Line != test.text {
+				t.Errorf("parseLogLine = %+v, want %+v with the full line", got, test.want)
+			}
+		})
+	}
+}
+
+func writeGzipLogFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(content))
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeLogFile(t, path, compressed.String(), modTime)
+}
+
+func TestSearchRotatedFiles(t *testing.T) {
+	useRotation(t, RotationConfig{Dir: t.TempDir(), Window: 10 * time.Minute})
+	at := func(minute int) time.Time { return time.Date(2025, 8, 9, 5, minute, 0, 0, time.Local) }
+	stamp := func(minute int) string { return at(minute).Format("2006-01-02 15:04:05.000") }
+
+	// ช่วง 05:00 ถูกบีบอัดแล้ว, ช่วง 05:10 มีสองไฟล์ (.1 คือไฟล์ที่หมุนเพราะขนาด) และมีบรรทัด JSON ปนอยู่
+	_, compressed := makePaths(at(0), 0)
+	writeGzipLogFile(t, compressed+".gz", strings.Join([]string{
+		stamp(1) + " [books] [info] listed",
+		stamp(2) + " [books] [warn] title exists title=Dune",
+		stamp(3) + " [api] [error] route timeout",
+	}, "\n")+"\n", at(3))
+	_, current := makePaths(at(10), 0)
+	writeLogFile(t, current, strings.Join([]string{
+		stamp(11) + " [api] [debug] request",
+		"not a log line",
+		stamp(12) + " [books] [warn] Title Exists title=Emma",
+		`{"time":"` + at(13).Format(time.RFC3339Nano) + `","level":"error","module":"books","msg":"db down"}`,
+	}, "\n")+"\n", at(13))
+	_, rotated := makePaths(at(10), 1)
+	writeLogFile(t, rotated, stamp(14)+" [cache] [warn] set failed\n", at(14))
+
+	tests := []struct {
+		name  string
+		query SearchQuery
+		want  []int // นาทีของบรรทัดที่ได้ ตามลำดับ
+	}{
+		{"everything in order", SearchQuery{Filter: Filter{Level: LevelTrace}}, []int{1, 2, 3, 11, 12, 13, 14}},
+		{"minimum level", SearchQuery{Filter: Filter{Level: LevelWarn}}, []int{2, 3, 12, 13, 14}},
+		{"module", SearchQuery{Filter: Filter{Level: LevelTrace, Module: "books"}}, []int{1, 2, 12, 13}},
+		{"text ignores case", SearchQuery{Filter: Filter{Level: LevelTrace, Text: "title exists"}}, []int{2, 12}},
+		{"time range", SearchQuery{Filter: Filter{Level: LevelTrace}, From: at(2), To: at(12)}, []int{2, 3, 11, 12}},
+		// ไฟล์ที่เริ่มหลัง To ไม่ถูกเปิด ไฟล์ที่แก้ไขล่าสุดก่อน From ก็เช่นกัน
+		{"range inside one window", SearchQuery{Filter: Filter{Level: LevelTrace}, From: at(13), To: at(14)}, []int{13, 14}},
+		{"limit", SearchQuery{Filter: Filter{Level: LevelWarn}, Limit: 2}, []int{2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []int
+			count, err := Search(context.Background(), test.query, func(line LogLine) error {
+				got = append(got, line.Time.In(time.Local).Minute())
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) || count != len(test.want) {
+				t.Errorf("minutes = %v (count %d), want %v", got, count, test.want)
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := Search(ctx, SearchQuery{}, func(LogLine) error { return nil }); err != context.Canceled {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -0,0 +1,404 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Record หนึ่งบรรทัด log ที่ส่งให้ทุก sink — Line คือข้อความตาม LOG_FORMAT (มี \n ท้าย)
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Module  string
+	Message string
+	Context []Field // request_id, trace_id, span_id จาก ctx
+	Fields  []Field
+	Line    []byte
+}
+
+func newRecord(module string, level Level, context []Field, message string, fields []Field) Record {
+	record := Record{
+		Time:    time.Now(),
+		Level:   level,
+		Module:  module,
+		Message: message,
+		Context: context,
+		Fields:  fields,
+	}
+	record.Line = encode(record.entry())
+	return record
+}
+
+func (record Record) entry() entry {
+	return entry{
+		time:    record.Time,
+		module:  record.Module,
+		level:   record.Level.String(),
+		message: record.Message,
+		context: record.Context,
+		fields:  record.Fields,
+	}
+}
+
+// JSON บรรทัดรูปแบบ json เสมอ (ไม่ขึ้นกับ LOG_FORMAT) ไม่มี \n ท้าย — ใช้กับปลายทางที่ต้องการเอกสาร JSON
+func (record Record) JSON() []byte {
+	var buffer bytes.Buffer
+	encodeJSON(&buffer, record.entry())
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n"))
+}
+
+// Sink ปลายทางของ log หนึ่งแห่ง
+//
+// Write ถูกเรียกบนเส้นทางของ request (โหมดปกติ) จึงต้องเร็ว — ปลายทางทางเครือข่ายควรแค่ใส่คิว
+// Flush ถูกเรียกหลังเขียนแต่ละชุด (โหมดปกติ = ทุกบรรทัด) และ Close ตอนปิดโปรแกรม
+// ทุกเมธอดถูกเรียกพร้อมกันจากหลาย goroutine ได้
+type Sink interface {
+	Write(record Record) error
+	Flush() error
+	Close() error
+}
+
+// SinkConfig sink หนึ่งตัวพร้อมชื่อ (ใช้ในข้อความ error) และระดับขั้นต่ำของ sink นี้
+// (กรองซ้ำหลัง LOG_LEVEL/LOG_LEVEL_MODULES เช่น ส่ง syslog เฉพาะ warn ขึ้นไป)
+type SinkConfig struct {
+	Name    string
+	Minimum Level
+	Sink    Sink
+}
+
+// sinks รายการปัจจุบันแบบ copy-on-write (อ่านทุกบรรทัดโดยไม่ต้องล็อก)
+var sinks atomic.Pointer[[]SinkConfig]
+
+func init() {
+	sinks.Store(&[]SinkConfig{{Name: "file", Minimum: LevelTrace, Sink: FileSink()}})
+}
+
+// SetSinks แทนที่ปลายทางทั้งหมด แล้วปิด sink เดิมที่ไม่อยู่ในรายการใหม่
+// เรียกตอนเริ่มโปรแกรมก่อน SetAsync (ระหว่างสลับ บรรทัดที่ค้างในคิวอาจไปลง sink เดิมที่กำลังปิด)
+func SetSinks(next []SinkConfig) {
+	previous := sinks.Swap(&next)
+	for _, old := range *previous {
+		kept := false
+		for _, current := range next {
+			if current.Sink == old.Sink {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			_ = old.Sink.Close()
+		}
+	}
+}
+
+// dispatch ส่งบรรทัดให้ทุก sink ที่ระดับผ่าน — sink ที่ล้มเหลวพิมพ์ลงคอนโซลกันหาย
+func dispatch(record Record) {
+	for _, sink := range *sinks.Load() {
+		if record.Level < sink.Minimum {
+			continue
+		}
+		if err := sink.Sink.Write(record); err != nil {
+			fmt.Printf("logger error: %s: %v | %s", sink.Name, err, record.Line)
+		}
+	}
+}
+
+// flushSinks ให้ทุก sink ส่งสิ่งที่ค้างใน buffer ออกไป
+func flushSinks() {
+	for _, sink := range *sinks.Load() {
+		_ = sink.Sink.Flush()
+	}
+}
+
+// closeSinks ปิดทุก sink (ไฟล์จะถูกเปิดใหม่เองถ้ามีบรรทัดเขียนเข้ามาอีก)
+func closeSinks() {
+	for _, sink := range *sinks.Load() {
+		_ = sink.Sink.Close()
+	}
+}
+
+// fileSink ไฟล์ที่หมุนตาม RotationConfig (สถานะไฟล์อยู่ใน log.go/rotate.go ใต้ mutex)
+type fileSink struct{}
+
+var defaultFileSink = &fileSink{}
+
+// FileSink ไฟล์ log ที่หมุนตาม SetRotation (มีตัวเดียวทั้งโปรแกรม)
+func FileSink() Sink {
+	return defaultFileSink
+}
+
+func (sink *fileSink) Write(record Record) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return writeLineLocked(record.Line)
+}
+
+func (sink *fileSink) Flush() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	flushLocked()
+	return nil
+}
+
+// Close ปิดไฟล์แล้วรองานบีบอัด/ลบไฟล์เก่าที่ค้างอยู่ให้เสร็จ
+func (sink *fileSink) Close() error {
+	mutex.Lock()
+	closeFileLocked()
+	mutex.Unlock()
+	waitHousekeeping()
+	return nil
+}
+
+// writerSink เขียนบรรทัดลง io.Writer ผ่าน buffer (เช่น stdout ใน container)
+type writerSink struct {
+	mutex  sync.Mutex
+	writer *bufio.Writer
+}
+
+// NewWriterSink sink ที่เขียนลง writer (เช่น os.Stdout)
+func NewWriterSink(writer io.Writer) Sink {
+	return &writerSink{writer: bufio.NewWriterSize(writer, 32<<10)}
+}
+
+func (sink *writerSink) Write(record Record) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	_, err := sink.writer.Write(record.Line)
+	return err
+}
+
+func (sink *writerSink) Flush() error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	return sink.writer.Flush()
+}
+
+func (sink *writerSink) Close() error {
+	return sink.Flush()
+}
+
+// RetryPolicy การลองส่งซ้ำของ sink ทางเครือข่าย: รอ Backoff แล้วเพิ่มเป็นสองเท่าจนถึง MaxBackoff
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// permanentError error ที่ส่งซ้ำไปก็ไม่สำเร็จ (เช่น HTTP 400) — ทิ้งชุดนั้นทันที
+type permanentError struct{ err error }
+
+func (err permanentError) Error() string { return err.err.Error() }
+func (err permanentError) Unwrap() error { return err.err }
+
+// remoteQueueSize จำนวนบรรทัดที่ sink ทางเครือข่ายค้างได้ เกินจากนี้ทิ้งแล้วนับใน DroppedLines
+const remoteQueueSize = 10000
+
+// remoteSink โครงร่วมของ sink ทางเครือข่าย: Write แค่ใส่คิว goroutine ของ sink รวมเป็นชุด
+// (ครบ batchSize หรือทุก interval) แล้วส่งด้วย send พร้อม retry/backoff
+type remoteSink struct {
+	name      string
+	queue     chan Record
+	stop      chan struct{}
+	done      chan struct{}
+	closed    atomic.Bool
+	closeOnce sync.Once
+	batchSize int
+	interval  time.Duration
+	retry     RetryPolicy
+	// send ส่งหนึ่งชุด ล้มเหลวคืนบรรทัดที่ยังต้องส่งซ้ำพร้อม err (บรรทัดที่ปลายทางปฏิเสธถาวร send ทิ้งและนับเอง)
+	send func(batch []Record) (remaining []Record, err error)
+	// release ปิดการเชื่อมต่อหลัง goroutine จบ (nil ได้)
+	release func()
+}
+
+func newRemoteSink(name string, batchSize int, interval time.Duration, retry RetryPolicy, send func([]Record) ([]Record, error), release func()) *remoteSink {
+	sink := &remoteSink{
+		name:      name,
+		queue:     make(chan Record, remoteQueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		batchSize: max(batchSize, 1),
+		interval:  interval,
+		retry:     retry,
+		send:      send,
+		release:   release,
+	}
+	if sink.interval <= 0 {
+		sink.interval = time.Second
+	}
+	sink.retry.Attempts = max(sink.retry.Attempts, 1)
+	go sink.run()
+	return sink
+}
+
+// Write ใส่คิวโดยไม่รอ — คิวเต็มหรือปิดแล้วทิ้งบรรทัดนั้น
+func (sink *remoteSink) Write(record Record) error {
+	if sink.closed.Load() {
+		return nil
+	}
+	select {
+	case sink.queue <- record:
+	default:
+		droppedLines.Add(1)
+	}
+	return nil
+}
+
+// Flush ไม่รอเครือข่าย (ส่งตามรอบของ goroutine)
+func (sink *remoteSink) Flush() error { return nil }
+
+// Close ส่งที่ค้างในคิวให้หมด (รอไม่เกิน 10 วินาที) แล้วปิดการเชื่อมต่อ
+func (sink *remoteSink) Close() error {
+	sink.closeOnce.Do(func() {
+		sink.closed.Store(true)
+		close(sink.stop)
+	})
+	select {
+	case <-sink.done:
+		return nil
+	case <-time.After(10 * time.Second):
+		return fmt.Errorf("sink %s: timed out flushing on close", sink.name)
+	}
+}
+
+func (sink *remoteSink) run() {
+	defer close(sink.done)
+	if sink.release != nil {
+		defer sink.release()
+	}
+	ticker := time.NewTicker(sink.interval)
+	defer ticker.Stop()
+	batch := make([]Record, 0, sink.batchSize)
+	for {
+		select {
+		case record := <-sink.queue:
+			batch = append(batch, record)
+			if len(batch) >= sink.batchSize {
+				sink.deliver(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				sink.deliver(batch)
+				batch = batch[:0]
+			}
+		case <-sink.stop:
+			for drained := false; !drained; {
+				select {
+				case record := <-sink.queue:
+					batch = append(batch, record)
+					if len(batch) >= sink.batchSize {
+						sink.deliver(batch)
+						batch = batch[:0]
+					}
+				default:
+					drained = true
+				}
+			}
+			if len(batch) > 0 {
+				sink.deliver(batch)
+			}
+			return
+		}
+	}
+}
+
+// deliver ส่งหนึ่งชุด ล้มเหลวลองใหม่ตาม RetryPolicy — หมดรอบหรือเป็น permanentError ทิ้งแล้วนับ
+func (sink *remoteSink) deliver(batch []Record) {
+	backoff := sink.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		remaining, err := sink.send(batch)
+		if err == nil || len(remaining) == 0 {
+			return
+		}
+		batch = remaining
+		var permanent permanentError
+		if attempt >= sink.retry.Attempts || errors.As(err, &permanent) {
+			droppedLines.Add(uint64(len(batch)))
+			fmt.Fprintf(os.Stderr, "logger: sink %s: dropped %d lines after %d attempts: %v\n", sink.name, len(batch), attempt, err)
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, max(sink.retry.MaxBackoff, sink.retry.Backoff))
+	}
+}
+
+// SinksFromEnv สร้างรายการ sink จาก env
+//
+//	LOG_SINKS            รายชื่อคั่นด้วย "," แต่ละตัวกำหนดระดับขั้นต่ำได้ เช่น "file,stdout:info,syslog:warn,http:error" (ค่าเริ่มต้น file)
+//	LOG_APP_NAME         ชื่อโปรแกรมใน syslog (APP-NAME) และ label app ของ Loki (go-101-basiccrud)
+//	LOG_SYSLOG_ADDR      udp://host:514 | tcp://host:601 | unix:///dev/log
+//	LOG_SYSLOG_FACILITY  เลข facility (16 = local0)
+//	LOG_HTTP_URL         ปลายทาง เช่น http://loki:3100/loki/api/v1/push หรือ http://es:9200/_bulk
+//	LOG_HTTP_FORMAT      loki | elasticsearch (loki), LOG_HTTP_INDEX (ว่าง = ใช้ index จาก URL เช่น /logs/_bulk), LOG_HTTP_AUTHORIZATION (ค่า header Authorization)
+//	LOG_HTTP_BATCH (500), LOG_HTTP_FLUSH_INTERVAL (1s)
+//	LOG_SINK_RETRIES (5), LOG_SINK_BACKOFF (200ms), LOG_SINK_MAX_BACKOFF (5s)
+func SinksFromEnv() ([]SinkConfig, error) {
+	retry := RetryPolicy{
+		Attempts:   envInt("LOG_SINK_RETRIES", 5),
+		Backoff:    envDuration("LOG_SINK_BACKOFF", 200*time.Millisecond),
+		MaxBackoff: envDuration("LOG_SINK_MAX_BACKOFF", 5*time.Second),
+	}
+	appName := strings.TrimSpace(os.Getenv("LOG_APP_NAME"))
+	if appName == "" {
+		appName = "go-101-basiccrud"
+	}
+
+	var configs []SinkConfig
+	for _, item := range envList("LOG_SINKS", "file") {
+		name, levelName, hasLevel := strings.Cut(item, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		minimum := LevelTrace
+		if hasLevel {
+			level, err := ParseLevel(levelName)
+			if err != nil {
+				return nil, fmt.Errorf("LOG_SINKS %s: %w", name, err)
+			}
+			minimum = level
+		}
+
+		var sink Sink
+		switch name {
+		case "file":
+			sink = FileSink()
+		case "stdout":
+			sink = NewWriterSink(os.Stdout)
+		case "syslog":
+			created, err := NewSyslogSink(SyslogConfig{
+				Address:  os.Getenv("LOG_SYSLOG_ADDR"),
+				AppName:  appName,
+				Facility: envInt("LOG_SYSLOG_FACILITY", 16),
+			}, retry)
+			if err != nil {
+				return nil, err
+			}
+			sink = created
+		case "http":
+			created, err := NewHTTPSink(HTTPSinkConfig{
+				URL:           os.Getenv("LOG_HTTP_URL"),
+				Format:        strings.ToLower(strings.TrimSpace(os.Getenv("LOG_HTTP_FORMAT"))),
+				Index:         os.Getenv("LOG_HTTP_INDEX"),
+				Authorization: os.Getenv("LOG_HTTP_AUTHORIZATION"),
+				AppName:       appName,
+				BatchSize:     envInt("LOG_HTTP_BATCH", 500),
+				FlushInterval: envDuration("LOG_HTTP_FLUSH_INTERVAL", time.Second),
+			}, retry)
+			if err != nil {
+				return nil, err
+			}
+			sink = created
+		default:
+			return nil, fmt.Errorf("unknown sink %q in LOG_SINKS (want file, stdout, syslog or http)", name)
+		}
+		configs = append(configs, SinkConfig{Name: name, Minimum: minimum, Sink: sink})
+	}
+	return configs, nil
+}
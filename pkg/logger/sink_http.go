@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ค่าของ LOG_HTTP_FORMAT
+const (
+	HTTPFormatLoki          = "loki"          // POST /loki/api/v1/push แยก stream ตาม level + module
+	HTTPFormatElasticsearch = "elasticsearch" // POST /_bulk แบบ NDJSON หนึ่งเอกสารต่อบรรทัด
+)
+
+// HTTPSinkConfig ปลายทาง HTTP ที่รับ log เป็นชุด
+type HTTPSinkConfig struct {
+	URL           string
+	Format        string // loki | elasticsearch (ว่าง = loki)
+	Index         string // _index ของ elasticsearch (ว่าง = ใช้ index จาก URL)
+	Authorization string // ค่า header Authorization (ว่าง = ไม่ส่ง)
+	AppName       string // label app ของ Loki
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// NewHTTPSink รวมบรรทัดเป็นชุด (ครบ BatchSize หรือทุก FlushInterval) แล้ว POST
+// 429/5xx และ error เครือข่ายลองใหม่ตาม retry ส่วน 4xx อื่นทิ้งชุดนั้น
+// elasticsearch ตรวจผลราย item ด้วย: ส่งซ้ำเฉพาะ item ที่ได้ 429/5xx (ดู bulkFailures)
+func NewHTTPSink(config HTTPSinkConfig, retry RetryPolicy) (Sink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("http sink: LOG_HTTP_URL is required")
+	}
+	if config.Format == "" {
+		config.Format = HTTPFormatLoki
+	}
+	var encodeBatch func([]Record) ([]byte, string, error)
+	switch config.Format {
+	case HTTPFormatLoki:
+		encodeBatch = config.encodeLoki
+	case HTTPFormatElasticsearch:
+		encodeBatch = config.encodeBulk
+	default:
+		return nil, fmt.Errorf("http sink: unknown LOG_HTTP_FORMAT=%q (want %s or %s)", config.Format, HTTPFormatLoki, HTTPFormatElasticsearch)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	send := func(batch []Record) ([]Record, error) {
+		body, contentType, err := encodeBatch(batch)
+		if err != nil {
+			return batch, permanentError{err}
+		}
+		request, err := http.NewRequest(http.MethodPost, config.URL, bytes.NewReader(body))
+		if err != nil {
+			return batch, permanentError{err}
+		}
+		request.Header.Set("Content-Type", contentType)
+		if config.Authorization != "" {
+			request.Header.Set("Authorization", config.Authorization)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return batch, err
+		}
+		defer response.Body.Close()
+		switch {
+		case response.StatusCode >= 200 && response.StatusCode < 300:
+			if config.Format == HTTPFormatElasticsearch {
+				return bulkFailures(batch, response.Body)
+			}
+			return nil, nil
+		case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+			return batch, fmt.Errorf("http sink: %s: %s", response.Status, responseDetail(response.Body))
+		default:
+			return batch, permanentError{fmt.Errorf("http sink: %s: %s", response.Status, responseDetail(response.Body))}
+		}
+	}
+	return newRemoteSink("http", config.BatchSize, config.FlushInterval, retry, send, client.CloseIdleConnections), nil
+}
+
+// responseDetail ต้นข้อความของ response ที่ล้มเหลว ใส่ใน error
+func responseDetail(body io.Reader) []byte {
+	detail, _ := io.ReadAll(io.LimitReader(body, 512))
+	return bytes.TrimSpace(detail)
+}
+
+// bulkFailures ตรวจผลของ _bulk — 200 ยังมีบางเอกสารล้มได้ ("errors":true และ status ราย item)
+// item ที่ได้ 429/5xx คืนไปส่งซ้ำ ส่วนที่ถูกปฏิเสธถาวร (เช่น 400 mapping ไม่ตรง) ทิ้งแล้วนับใน DroppedLines
+// อ่านผลไม่ได้ถือว่าส่งแล้ว (ส่งซ้ำอาจได้เอกสารซ้ำ) แต่แจ้งทาง stderr
+func bulkFailures(batch []Record, body io.Reader) ([]Record, error) {
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: sink http: cannot read _bulk response: %v\n", err)
+		return nil, nil
+	}
+	if !result.Errors {
+		return nil, nil
+	}
+	if len(result.Items) != len(batch) {
+		fmt.Fprintf(os.Stderr, "logger: sink http: _bulk returned %d items for %d lines\n", len(result.Items), len(batch))
+		return nil, nil
+	}
+
+	var retry []Record
+	var rejected int
+	var reason json.RawMessage
+	for index, item := range result.Items {
+		// item มี key เดียวตาม action ({"index":{...}})
+		for _, outcome := range item {
+			switch {
+			case outcome.Status == http.StatusTooManyRequests || outcome.Status >= 500:
+				retry = append(retry, batch[index])
+			case outcome.Status >= 300:
+				rejected++
+			default:
+				continue
+			}
+			if reason == nil {
+				reason = outcome.Error
+			}
+		}
+	}
+	if rejected > 0 {
+		droppedLines.Add(uint64(rejected))
+		fmt.Fprintf(os.Stderr, "logger: sink http: elasticsearch rejected %d lines: %s\n", rejected, reason)
+	}
+	if len(retry) > 0 {
+		return retry, fmt.Errorf("http sink: elasticsearch failed %d of %d items: %s", len(retry), len(batch), reason)
+	}
+	return nil, nil
+}
+
+// encodeLoki {"streams":[{"stream":{"app":...,"level":...,"module":...},"values":[["<unix ns>","<line>"],...]}]}
+func (config HTTPSinkConfig) encodeLoki(batch []Record) ([]byte, string, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	var streams []*stream
+	byLabels := map[[2]string]*stream{}
+	for _, record := range batch {
+		key := [2]string{record.Level.String(), record.Module}
+		current, ok := byLabels[key]
+		if !ok {
+			labels := map[string]string{"level": key[0], "module": key[1]}
+			if config.AppName != "" {
+				labels["app"] = config.AppName
+			}
+			current = &stream{Stream: labels}
+			byLabels[key] = current
+			streams = append(streams, current)
+		}
+		line := string(bytes.TrimSuffix(record.Line, []byte("\n")))
+		current.Values = append(current.Values, [2]string{strconv.FormatInt(record.Time.UnixNano(), 10), line})
+	}
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	return body, "application/json", err
+}
+
+// encodeBulk NDJSON ของ bulk API: บรรทัด action {"index":{...}} ตามด้วยเอกสาร (Record.JSON) และจบด้วย \n
+func (config HTTPSinkConfig) encodeBulk(batch []Record) ([]byte, string, error) {
+	action := []byte(`{"index":{}}`)
+	if config.Index != "" {
+		encoded, err := json.Marshal(map[string]any{"index": map[string]string{"_index": config.Index}})
+		if err != nil {
+			return nil, "", err
+		}
+		action = encoded
+	}
+	var buffer bytes.Buffer
+	for _, record := range batch {
+		buffer.Write(action)
+		buffer.WriteByte('\n')
+		buffer.Write(record.JSON())
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes(), "application/x-ndjson", nil
+}
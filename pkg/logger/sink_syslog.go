@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogConfig ปลายทาง syslog แบบ RFC 5424
+type SyslogConfig struct {
+	// Address udp://host:514 | tcp://host:601 | unix:///dev/log (unix = datagram แบบ /dev/log)
+	Address  string
+	AppName  string
+	Facility int // 0–23 เช่น 1 = user, 16 = local0
+}
+
+// syslogDialTimeout เวลาสูงสุดในการเชื่อมต่อและการเขียนแต่ละข้อความ
+const syslogDialTimeout = 5 * time.Second
+
+// NewSyslogSink ส่งแต่ละบรรทัดเป็นข้อความ RFC 5424 — TCP ใช้ octet counting (RFC 6587)
+// เชื่อมต่อเมื่อมีข้อความแรก ขาดแล้วต่อใหม่ตาม retry
+func NewSyslogSink(config SyslogConfig, retry RetryPolicy) (Sink, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("syslog sink: LOG_SYSLOG_ADDR is required")
+	}
+	parsed, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("syslog sink: %w", err)
+	}
+	var network, address string
+	switch parsed.Scheme {
+	case "udp", "tcp":
+		network, address = parsed.Scheme, parsed.Host
+	case "unix", "unixgram":
+		network, address = "unixgram", parsed.Path
+	default:
+		return nil, fmt.Errorf("syslog sink: unknown scheme %q (want udp, tcp or unix)", parsed.Scheme)
+	}
+	if config.Facility < 0 || config.Facility > 23 {
+		return nil, fmt.Errorf("syslog sink: facility %d out of range 0-23", config.Facility)
+	}
+	if config.AppName == "" {
+		config.AppName = "-"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	writer := &syslogWriter{
+		config:   config,
+		network:  network,
+		address:  address,
+		hostname: hostname,
+		pid:      strconv.Itoa(os.Getpid()),
+	}
+	// batch ของ syslog แค่ลดการปลุก goroutine — ยังส่งทีละข้อความ
+	return newRemoteSink("syslog", 100, 200*time.Millisecond, retry, writer.send, writer.close), nil
+}
+
+// syslogWriter การเชื่อมต่อของ sink (ใช้จาก goroutine ของ remoteSink ตัวเดียว ไม่ต้องล็อก)
+type syslogWriter struct {
+	config   SyslogConfig
+	network  string
+	address  string
+	hostname string
+	pid      string
+	conn     net.Conn
+}
+
+func (writer *syslogWriter) send(batch []Record) ([]Record, error) {
+	if writer.conn == nil {
+		conn, err := net.DialTimeout(writer.network, writer.address, syslogDialTimeout)
+		if err != nil {
+			return batch, err
+		}
+		writer.conn = conn
+	}
+	for index, record := range batch {
+		message := writer.format(record)
+		if writer.network == "tcp" {
+			message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+		}
+		_ = writer.conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+		if _, err := writer.conn.Write(message); err != nil {
+			// ปิดแล้วต่อใหม่รอบถัดไป (เช่น server restart)
+			writer.close()
+			return batch[index:], err
+		}
+	}
+	return nil, nil
+}
+
+func (writer *syslogWriter) close() {
+	if writer.conn != nil {
+		_ = writer.conn.Close()
+		writer.conn = nil
+	}
+}
+
+// format <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [ctx@32473 request_id="..."] MSG
+// MSGID คือโมดูล ค่าจาก ctx อยู่ใน structured data ส่วน field อื่นต่อท้ายข้อความแบบ key=value
+func (writer *syslogWriter) format(record Record) []byte {
+	var buffer bytes.Buffer
+	priority := writer.config.Facility*8 + syslogSeverity(record.Level)
+	fmt.Fprintf(&buffer, "<%d>1 %s %s %s %s %s ",
+		priority,
+		record.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		writer.hostname,
+		syslogName(writer.config.AppName, 48),
+		writer.pid,
+		syslogName(record.Module, 32),
+	)
+	if len(record.Context) == 0 {
+		buffer.WriteString("-")
+	} else {
+		buffer.WriteString("[ctx@32473")
+		for _, field := range record.Context {
+			value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fmt.Sprint(field.Value))
+			fmt.Fprintf(&buffer, " %s=\"%s\"", syslogName(field.Key, 32), value)
+		}
+		buffer.WriteString("]")
+	}
+	buffer.WriteString(" " + strings.ReplaceAll(record.Message, "\n", " "))
+	writeTextFields(&buffer, record.Fields)
+	return buffer.Bytes()
+}
+
+// syslogSeverity ระดับของเรา → severity ของ syslog (trace/debug = 7 debug, info = 6, warn = 4, error = 3)
+func syslogSeverity(level Level) int {
+	switch {
+	case level >= LevelError:
+		return 3
+	case level >= LevelWarn:
+		return 4
+	case level >= LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// syslogName ค่าของ header ต้องเป็น ASCII ที่พิมพ์ได้ไม่มีช่องว่าง และยาวไม่เกิน limit ("-" ถ้าว่าง)
+func syslogName(value string, limit int) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, value)
+	if cleaned == "" {
+		return "-"
+	}
+	if len(cleaned) > limit {
+		cleaned = cleaned[:limit]
+	}
+	return cleaned
+}
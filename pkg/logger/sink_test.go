@@ -0,0 +1,249 @@
+package logger_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// fastRetry ลองซ้ำเร็วๆ ให้เทสต์ไม่ต้องรอ backoff จริง
+var fastRetry = logger.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func testRecord(message string) logger.Record {
+	return logger.Record{
+		Time:    time.Date(2025, 8, 9, 5, 1, 14, 533000000, time.UTC),
+		Level:   logger.LevelWarn,
+		Module:  "books",
+		Message: message,
+		Context: []logger.Field{logger.String("request_id", "req-1")},
+		Fields:  []logger.Field{logger.Int("status", 409)},
+		Line:    []byte("2025-08-09 05:01:14.533 [books] [warn] " + message + "\n"),
+	}
+}
+
+// checkSyslogMessage ตรวจ header ของ RFC 5424: facility 16 (local0) * 8 + warn (4) = 132
+func checkSyslogMessage(t *testing.T, message, text string) {
+	t.Helper()
+	for _, want := range []string{"<132>1 2025-08-09T05:01:14.533000Z ", " books-test ", " books [ctx@32473 request_id=\"req-1\"] " + text + " status=409"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message %q does not contain %q", message, want)
+		}
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	sink, err := logger.NewSyslogSink(logger.SyslogConfig{Address: "udp://" + listener.LocalAddr().String(), AppName: "books-test", Facility: 16}, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.Write(testRecord("title exists"))
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buffer := make([]byte, 2048)
+	size, _, err := listener.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSyslogMessage(t, string(buffer[:size]), "title exists")
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		// octet counting (RFC 6587): "<ความยาว> <ข้อความ>" ต่อกันไปจนปิดการเชื่อมต่อ
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for {
+			prefix, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			length, err := strconv.Atoi(strings.TrimSpace(prefix))
+			if err != nil {
+				break
+			}
+			message := make([]byte, length)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	sink, err := logger.NewSyslogSink(logger.SyslogConfig{Address: "tcp://" + listener.Addr().String(), AppName: "books-test", Facility: 16}, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.Write(testRecord("first"))
+	_ = sink.Write(testRecord("second"))
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := <-received
+	if len(messages) != 2 {
+		t.Fatalf("received %d framed messages %q, want 2", len(messages), messages)
+	}
+	checkSyslogMessage(t, messages[0], "first")
+	checkSyslogMessage(t, messages[1], "second")
+}
+
+func TestHTTPSinkLoki(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		bodies []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/loki/api/v1/push" || request.Header.Get("Content-Type") != "application/json" || request.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %s %s %v", request.Method, request.URL, request.Header)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		mutex.Lock()
+		bodies = append(bodies, body)
+		mutex.Unlock()
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := logger.NewHTTPSink(logger.HTTPSinkConfig{
+		URL: server.URL + "/loki/api/v1/push", Format: logger.HTTPFormatLoki, Authorization: "Bearer secret",
+		AppName: "books-test", BatchSize: 10, FlushInterval: time.Hour,
+	}, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.Write(testRecord("title exists"))
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d pushes, want 1", len(bodies))
+	}
+	encoded, _ := json.Marshal(bodies[0])
+	want := `{"streams":[{"stream":{"app":"books-test","level":"warn","module":"books"},"values":[["1754715674533000000","2025-08-09 05:01:14.533 [books] [warn] title exists"]]}]}`
+	if string(encoded) != want {
+		t.Errorf("push body = %s\nwant %s", encoded, want)
+	}
+}
+
+// 200 จาก _bulk ที่มี "errors":true: item ที่ได้ 429 ส่งซ้ำ item ที่ได้ 400 ทิ้งแล้วนับ
+func TestHTTPSinkElasticsearchPartialFailure(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		requests [][]string // ข้อความของเอกสารในแต่ละ request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/logs/_bulk" || request.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("unexpected request %s %s", request.URL, request.Header.Get("Content-Type"))
+		}
+		lines := strings.Split(strings.TrimSuffix(readAll(t, request.Body), "\n"), "\n")
+		var messages []string
+		var items []string
+		for index := 1; index < len(lines); index += 2 {
+			if lines[index-1] != `{"index":{}}` {
+				t.Errorf("action line = %s", lines[index-1])
+			}
+			var document struct {
+				Message string `json:"msg"`
+			}
+			if err := json.Unmarshal([]byte(lines[index]), &document); err != nil {
+				t.Errorf("document %s: %v", lines[index], err)
+			}
+			messages = append(messages, document.Message)
+			switch document.Message {
+			case "rejected once":
+				if countRequests(&mutex, &requests) == 0 {
+					items = append(items, `{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}`)
+					continue
+				}
+			case "bad mapping":
+				items = append(items, `{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}`)
+				continue
+			}
+			items = append(items, `{"index":{"status":201}}`)
+		}
+		mutex.Lock()
+		requests = append(requests, messages)
+		mutex.Unlock()
+		errors := strings.Contains(strings.Join(items, ""), `"error"`)
+		_, _ = io.WriteString(writer, `{"took":3,"errors":`+strconv.FormatBool(errors)+`,"items":[`+strings.Join(items, ",")+`]}`)
+	}))
+	defer server.Close()
+
+	sink, err := logger.NewHTTPSink(logger.HTTPSinkConfig{
+		URL: server.URL + "/logs/_bulk", Format: logger.HTTPFormatElasticsearch, BatchSize: 10, FlushInterval: time.Hour,
+	}, fastRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	droppedBefore := logger.DroppedLines()
+	for _, message := range []string{"indexed", "rejected once", "bad mapping"} {
+		_ = sink.Write(testRecord(message))
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	want := [][]string{{"indexed", "rejected once", "bad mapping"}, {"rejected once"}}
+	if len(requests) != len(want) || strings.Join(requests[0], ",") != strings.Join(want[0], ",") || strings.Join(requests[1], ",") != strings.Join(want[1], ",") {
+		t.Fatalf("requests = %q, want %q", requests, want)
+	}
+	if dropped := logger.DroppedLines() - droppedBefore; dropped != 1 {
+		t.Errorf("dropped = %d, want 1 (bad mapping)", dropped)
+	}
+}
+
+func countRequests(mutex *sync.Mutex, requests *[][]string) int {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return len(*requests)
+}
+
+func readAll(t *testing.T, reader io.Reader) string {
+	t.Helper()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
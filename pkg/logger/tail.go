@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Subscription สำเนาบรรทัด log สด (ใช้กับ tail) — client อ่านช้าจนช่องเต็ม บรรทัดนั้นถูกข้ามแล้วนับไว้
+type Subscription struct {
+	Records <-chan Record
+
+	records   chan Record
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+}
+
+// subscribers ผู้รับทั้งหมด — write ส่งแบบไม่รอโดยถือ RLock จึงปิดช่อง (ถือ Lock) ได้อย่างปลอดภัย
+var (
+	subscribersMutex sync.RWMutex
+	subscribers      = map[*Subscription]struct{}{}
+	subscriberCount  atomic.Int32 // ทางลัด: ไม่มีผู้รับก็ไม่แตะ lock
+)
+
+// Subscribe รับทุกบรรทัดที่ผ่านระดับ log นับจากนี้ (buffer = จำนวนที่ค้างได้) — ต้องเรียก Close เมื่อเลิกใช้
+func Subscribe(buffer int) *Subscription {
+	records := make(chan Record, max(buffer, 1))
+	subscription := &Subscription{Records: records, records: records}
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	subscribers[subscription] = struct{}{}
+	subscriberCount.Add(1)
+	return subscription
+}
+
+// TakeDropped จำนวนบรรทัดที่ถูกข้ามตั้งแต่เรียกครั้งก่อน
+func (subscription *Subscription) TakeDropped() uint64 {
+	return subscription.dropped.Swap(0)
+}
+
+// Close เลิกรับ แล้วปิด Records (เรียกซ้ำได้)
+func (subscription *Subscription) Close() {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	subscription.closeLocked()
+}
+
+func (subscription *Subscription) closeLocked() {
+	subscription.closeOnce.Do(func() {
+		delete(subscribers, subscription)
+		subscriberCount.Add(-1)
+		close(subscription.records)
+	})
+}
+
+// CloseSubscribers ปิดทุก subscription (ให้ stream แบบไม่มีวันจบเองอย่าง tail จบตอนปิด server)
+func CloseSubscribers() {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	for subscription := range subscribers {
+		subscription.closeLocked()
+	}
+}
+
+// publish ส่งสำเนาให้ทุกผู้รับแบบไม่รอ (เรียกจาก write ก่อนเข้าคิว/sink)
+func publish(record Record) {
+	if subscriberCount.Load() == 0 {
+		return
+	}
+	subscribersMutex.RLock()
+	defer subscribersMutex.RUnlock()
+	for subscription := range subscribers {
+		select {
+		case subscription.records <- record:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package metrics ตัวชี้วัดรูปแบบ Prometheus ของทุกชั้น (HTTP, service, DB) อยู่ใน registry เดียว เสิร์ฟที่ /metrics
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry ของโปรแกรม (ไม่ใช้ default registry กัน library อื่นแอบลงทะเบียนเพิ่ม)
+var registry = prometheus.NewRegistry()
+
+// bucket ของ latency (วินาที) — 1ms ถึง ~10s
+var latencyBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "จำนวน HTTP request แยกตาม method, route และ status",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "เวลาตอบ HTTP request",
+		Buckets: latencyBuckets,
+	}, []string{"method", "route", "status"})
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "จำนวน HTTP request ที่กำลังทำงานอยู่",
+	})
+
+	booksCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "books_created_total",
+		Help: "จำนวนหนังสือที่สร้างสำเร็จ",
+	})
+	titleConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "books_title_conflicts_total",
+		Help: "จำนวนครั้งที่ปฏิเสธเพราะชื่อหนังสือซ้ำ แยกตาม operation (create/update)",
+	}, []string{"operation"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "เวลาของคำสั่ง SQL ที่ผ่าน GORM แยกตาม operation, table และผลลัพธ์ (ok/error)",
+		Buckets: latencyBuckets,
+	}, []string{"operation", "table", "result"})
+
+	caches = &cacheCollector{sources: map[string]func() (hits, misses uint64){}}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight,
+		booksCreated, titleConflicts,
+		dbQueryDuration,
+		caches,
+	)
+}
+
+// Handler เสิร์ฟ registry ในรูปแบบ Prometheus exposition
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// MustRegister เพิ่ม collector ของระบบย่อย (เช่น pool ของฐานข้อมูล) — ชื่อซ้ำจะ panic ตอนเริ่มโปรแกรม
+func MustRegister(collector ...prometheus.Collector) {
+	registry.MustRegister(collector...)
+}
+
+// BookCreated นับหนังสือที่สร้างสำเร็จ
+func BookCreated() { booksCreated.Inc() }
+
+// TitleConflict นับการปฏิเสธเพราะชื่อซ้ำ (operation = create | update)
+func TitleConflict(operation string) { titleConflicts.WithLabelValues(operation).Inc() }
+
+// ObserveQuery บันทึกเวลาของคำสั่ง SQL หนึ่งคำสั่ง
+func ObserveQuery(operation, table string, duration time.Duration, failed bool) {
+	result := "ok"
+	if failed {
+		result = "error"
+	}
+	dbQueryDuration.WithLabelValues(operation, table, result).Observe(duration.Seconds())
+}
+
+// RegisterCache ส่งออกจำนวน hit/miss ของ cache ชื่อ name (label cache) โดยอ่านจาก stats ตอนถูก scrape
+func RegisterCache(name string, stats func() (hits, misses uint64)) {
+	caches.mutex.Lock()
+	defer caches.mutex.Unlock()
+	caches.sources[name] = stats
+}
+
+// RegisterLogWriter ส่งออกสถานะของ logger: จำนวนบรรทัดที่ถูกทิ้ง (log_lines_dropped_total)
+// และจำนวนที่รอเขียนในคิว (log_queue_length) — อ่านค่าตอนถูก scrape
+func RegisterLogWriter(dropped func() uint64, queued func() int) {
+	registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "log_lines_dropped_total",
+			Help: "จำนวนบรรทัด log ที่ถูกทิ้ง (คิว async เต็มตามนโยบาย drop/sample หรือ sink ทางเครือข่ายส่งไม่สำเร็จ)",
+		}, func() float64 { return float64(dropped()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "log_queue_length",
+			Help: "จำนวนบรรทัด log ที่รอเขียนในคิว async",
+		}, func() float64 { return float64(queued()) }),
+	)
+}
+
+var cacheLookupsDesc = prometheus.NewDesc("cache_lookups_total",
+	"จำนวนครั้งที่อ่าน cache แยกตามชื่อ cache และผล (hit/miss)", []string{"cache", "result"}, nil)
+
+// cacheCollector อ่านตัวนับจาก cache ตรงๆ ตอน scrape (cache นับเองอยู่แล้ว ไม่ต้องนับซ้ำ)
+type cacheCollector struct {
+	mutex   sync.RWMutex
+	sources map[string]func() (hits, misses uint64)
+}
+
+func (collector *cacheCollector) Describe(descs chan<- *prometheus.Desc) { descs <- cacheLookupsDesc }
+
+func (collector *cacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	collector.mutex.RLock()
+	defer collector.mutex.RUnlock()
+	for name, stats := range collector.sources {
+		hits, misses := stats()
+		metrics <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(hits), name, "hit")
+		metrics <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(misses), name, "miss")
+	}
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute label ของ request ที่ไม่ตรง route ใด (ไม่ใช้ path จริง กัน label ระเบิดจาก URL สุ่ม)
+const unmatchedRoute = "unmatched"
+
+// Middleware นับ request, วัด latency และจำนวนที่กำลังทำงาน แยกตาม method, route (FullPath) และ status
+func Middleware() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		context.Next()
+
+		route := context.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(context.Writer.Status())
+		method := context.Request.Method
+		httpRequests.WithLabelValues(method, route, status).Inc()
+		httpDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
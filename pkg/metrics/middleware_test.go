@@ -0,0 +1,47 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/metrics"
+)
+
+// label route ต้องเป็น template ของ route (/books/:id) ไม่ใช่ path จริง — ไม่เช่นนั้นทุก id สร้าง series ใหม่
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(metrics.Middleware())
+	engine.GET("/api/v2/books/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	for _, path := range []string{"/api/v2/books/41", "/api/v2/books/42", "/random/8f14e45f"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("/metrics = %d", recorder.Code)
+	}
+	scraped := recorder.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/api/v2/books/:id",status="404"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/api/v2/books/:id",status="404"} 2`,
+		`http_requests_in_flight 1`, // scrape นี้เอง
+	} {
+		if !strings.Contains(scraped, want) {
+			t.Errorf("scrape does not contain %s", want)
+		}
+	}
+	for _, raw := range []string{`route="/api/v2/books/41"`, `route="/random/8f14e45f"`} {
+		if strings.Contains(scraped, raw) {
+			t.Errorf("scrape contains raw path label %s", raw)
+		}
+	}
+}
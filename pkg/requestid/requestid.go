@@ -0,0 +1,54 @@
+// Package requestid เก็บ/อ่าน request ID ใน context.Context ให้ทุกชั้น (handler, service, repository, logger) ใช้ร่วมกัน
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header ชื่อ header ที่รับเข้าและตอบกลับ
+const Header = "X-Request-ID"
+
+// maxLength ความยาวสูงสุดของ ID ที่รับจาก client (ยาวกว่านี้จะสร้างใหม่)
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewContext คืน context ใหม่ที่มี request ID
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext คืน request ID ใน ctx ("" ถ้าไม่มี)
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
+
+// Generate สร้าง ID สุ่ม 128 บิตในรูป hex 32 ตัวอักษร
+func Generate() string {
+	var buffer [16]byte
+	_, _ = rand.Read(buffer[:])
+	return hex.EncodeToString(buffer[:])
+}
+
+// Valid ตรวจว่า ID จาก client ปลอดภัยจะใส่ใน log/SQL comment ได้
+// อนุญาตเฉพาะ A-Z a-z 0-9 และ - _ . : ความยาว 1-128
+func Valid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxLength {
+		return false
+	}
+	for _, char := range requestID {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		case char == '-', char == '_', char == '.', char == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		want      bool
+	}{
+		{"generated", requestid.Generate(), true},
+		{"uuid", "3f2b1c9e-8a4d-4e6f-9b1a-2c3d4e5f6a7b", true},
+		{"allowed punctuation", "gw.01:req_42-a", true},
+		{"max length", strings.Repeat("a", 128), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"space", "req 1", false},
+		{"newline", "req\nfake=1", false},
+		{"comment close", "req*/DROP", false},
+		{"quote", `req"1`, false},
+		{"equals", "req=1", false},
+		{"non-ascii", "รหัส1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := requestid.Valid(test.requestID); got != test.want {
+				t.Errorf("Valid(%q) = %v, want %v", test.requestID, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		requestID := requestid.Generate()
+		if len(requestID) != 32 || strings.Trim(requestID, "0123456789abcdef") != "" {
+			t.Fatalf("Generate() = %q, want 32 lowercase hex characters", requestID)
+		}
+		if seen[requestID] {
+			t.Fatalf("Generate() repeated %q", requestID)
+		}
+		seen[requestID] = true
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := requestid.FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext(empty) = %q, want empty", got)
+	}
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	if got := requestid.FromContext(ctx); got != "req-1" {
+		t.Errorf("FromContext = %q, want req-1", got)
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+)
+
+// Middleware สร้าง server span ต่อ request ต่อจาก traceparent ที่ client ส่งมา (ถ้ามี)
+// แล้วใส่ span ลง c.Request.Context() ให้ service/GORM สร้าง child span และ logger ดึง trace ID ได้
+// ตอบ traceparent กลับใน response ให้ client จับคู่กับ trace ได้
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// ชื่อ span ใช้ route template (ไม่ใช่ path จริง) กันชื่อกระจายตาม :id
+		route := c.FullPath()
+		spanName := c.Request.Method
+		if route != "" {
+			spanName += " " + route
+		}
+		attributes := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+			semconv.ClientAddress(c.ClientIP()),
+			semconv.UserAgentOriginal(c.Request.UserAgent()),
+		}
+		if route != "" {
+			attributes = append(attributes, semconv.HTTPRoute(route))
+		}
+		if requestID := requestid.FromContext(ctx); requestID != "" {
+			attributes = append(attributes, attribute.String("request.id", requestID))
+		}
+
+		ctx, span := Tracer().Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+		defer span.End()
+
+		propagator.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// ตาม semconv ของ server span: เฉพาะ 5xx ถือว่า error (4xx เป็นความผิดของ client)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.SetAttributes(attribute.String("gin.errors", c.Errors.String()))
+		}
+	}
+}
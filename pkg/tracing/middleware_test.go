@@ -0,0 +1,178 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/http/middleware"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/requestid"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/tracing"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// recorder เก็บทุก span ที่จบแล้ว (ตั้งเป็น provider ของทั้ง process เพราะ tracing.Tracer อ่านจาก global)
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.SetSinks(nil)
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	os.Exit(m.Run())
+}
+
+// brokenBookService GetByID ล้มแบบ internal (เช่น DB ล่ม)
+type brokenBookService struct {
+	service.BookService
+}
+
+func (brokenBookService) GetByID(context.Context, uint) (*models.Book, error) {
+	return nil, apperr.New(apperr.Internal, "database down")
+}
+
+func newEngine(bookService service.BookService) *gin.Engine {
+	bookService = service.NewTracedBookService(bookService)
+	engine := gin.New()
+	engine.Use(middleware.RequestID(), tracing.Middleware())
+	engine.GET("/books/:id", func(c *gin.Context) {
+		bookID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+		book, err := bookService.GetByID(c.Request.Context(), uint(bookID))
+		switch {
+		case apperr.IsKind(err, apperr.NotFound):
+			c.Status(http.StatusNotFound)
+		case err != nil:
+			c.Status(http.StatusInternalServerError)
+		default:
+			c.JSON(http.StatusOK, book)
+		}
+	})
+	return engine
+}
+
+// spansOf span ของ request เดียว: server span กับ service span ที่เป็นลูก
+func spansOf(t *testing.T) (server, child sdktrace.ReadOnlySpan) {
+	t.Helper()
+	spans := recorder.Ended()
+	if len(spans) < 2 {
+		t.Fatalf("got %d ended spans, want a service span and a server span", len(spans))
+	}
+	// service span จบก่อน server span
+	child, server = spans[len(spans)-2], spans[len(spans)-1]
+	if server.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("last span %q kind = %s, want server", server.Name(), server.SpanKind())
+	}
+	return server, child
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestSpans(t *testing.T) {
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	book, err := bookService.Create(context.Background(), dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		service     service.BookService
+		path        string
+		wantStatus  int
+		serverCode  codes.Code
+		childCode   codes.Code
+		wantErrKind string // ค่า error.kind บน service span ("" = ไม่มี)
+	}{
+		{"found", bookService, "/books/" + strconv.Itoa(int(book.ID)), http.StatusOK, codes.Unset, codes.Unset, ""},
+		// ไม่พบเป็น error ธุรกิจ และ 4xx ไม่ใช่ความผิดของ server: ไม่มี span ไหนเป็น error
+		{"not found", bookService, "/books/999", http.StatusNotFound, codes.Unset, codes.Unset, "not_found"},
+		{"internal error", brokenBookService{bookService}, "/books/1", http.StatusInternalServerError, codes.Error, codes.Error, "internal"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			request.Header.Set(requestid.Header, "req-1")
+			newEngine(test.service).ServeHTTP(response, request)
+			if response.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", response.Code, test.wantStatus)
+			}
+			server, child := spansOf(t)
+
+			// ชื่อใช้ route template ส่วน path จริงอยู่ใน attribute
+			if server.Name() != "GET /books/:id" || child.Name() != "BookService.GetByID" {
+				t.Errorf("span names = %q, %q; want GET /books/:id and BookService.GetByID", server.Name(), child.Name())
+			}
+			for key, want := range map[attribute.Key]string{
+				"http.route":          "/books/:id",
+				"http.request.method": "GET",
+				"url.path":            test.path,
+				"request.id":          "req-1",
+			} {
+				if value, ok := attributeValue(server, key); !ok || value.AsString() != want {
+					t.Errorf("server span %s = %q, want %q", key, value.AsString(), want)
+				}
+			}
+			if value, _ := attributeValue(server, "http.response.status_code"); value.AsInt64() != int64(test.wantStatus) {
+				t.Errorf("server span status code = %d, want %d", value.AsInt64(), test.wantStatus)
+			}
+
+			// service span เป็นลูกของ server span ใน trace เดียวกัน
+			if child.Parent().SpanID() != server.SpanContext().SpanID() || child.SpanContext().TraceID() != server.SpanContext().TraceID() {
+				t.Errorf("service span parent = %s in trace %s, want server span %s in trace %s",
+					child.Parent().SpanID(), child.SpanContext().TraceID(), server.SpanContext().SpanID(), server.SpanContext().TraceID())
+			}
+			if value, ok := attributeValue(child, "error.kind"); value.AsString() != test.wantErrKind || ok != (test.wantErrKind != "") {
+				t.Errorf("service span error.kind = %q, want %q", value.AsString(), test.wantErrKind)
+			}
+			if server.Status().Code != test.serverCode || child.Status().Code != test.childCode {
+				t.Errorf("status codes server = %s service = %s, want %s and %s", server.Status().Code, child.Status().Code, test.serverCode, test.childCode)
+			}
+		})
+	}
+}
+
+// traceparent จาก client: server span ต่อ trace เดิม และตอบ traceparent ของ span ใหม่กลับไป
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	newEngine(bookService).ServeHTTP(response, request)
+
+	server, _ := spansOf(t)
+	if server.SpanContext().TraceID().String() != traceID || server.Parent().SpanID().String() != parentID || !server.Parent().IsRemote() {
+		t.Errorf("server span trace = %s parent = %s, want remote parent %s in trace %s",
+			server.SpanContext().TraceID(), server.Parent().SpanID(), parentID, traceID)
+	}
+	want := "00-" + traceID + "-" + server.SpanContext().SpanID().String() + "-01"
+	if got := response.Header().Get("traceparent"); got != want {
+		t.Errorf("response traceparent = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Package tracing ตั้งค่า OpenTelemetry tracing (exporter, propagator) และ middleware สร้าง server span ของ Gin
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName ชื่อ tracer ของโปรแกรม (ทุก span ที่เราสร้างเองใช้ชื่อนี้)
+const instrumentationName = "github.com/nuba55yo/go-101-BasicCRUD"
+
+// ค่าของ TRACING_EXPORTER
+const (
+	ExporterNone   = "none"   // ไม่ส่งออก (ยังสร้าง trace ID ให้ log และส่งต่อ traceparent)
+	ExporterOTLP   = "otlp"   // OTLP/HTTP — ปลายทางตาม OTEL_EXPORTER_OTLP_ENDPOINT (ค่าเริ่มต้น localhost:4318)
+	ExporterStdout = "stdout" // JSON ลง stdout
+	ExporterFile   = "file"   // JSON ต่อท้ายไฟล์ TRACING_FILE (ใช้ตอน offline / ทดสอบ)
+)
+
+// defaultServiceName ชื่อ service ใน resource ถ้าไม่ได้ตั้ง OTEL_SERVICE_NAME
+const defaultServiceName = "go-101-basiccrud"
+
+// Tracer tracer ของโปรแกรมจาก provider ที่ตั้งไว้ (ก่อน Setup เป็น no-op)
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup ตั้ง TracerProvider และ propagator (W3C traceparent + baggage) แบบ global จาก env
+//
+//	TRACING_EXPORTER  none | otlp | stdout | file (ค่าเริ่มต้น none)
+//	TRACING_FILE      ไฟล์ของ exporter file (ค่าเริ่มต้น logs/traces.jsonl)
+//
+// ค่ามาตรฐานของ OTel ใช้ได้ตามปกติ เช่น OTEL_SERVICE_NAME, OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER
+// คืน shutdown สำหรับส่ง span ที่ค้างอยู่ออกก่อนปิดโปรแกรม
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("TRACING_EXPORTER")))
+	if name == "" {
+		name = ExporterNone
+	}
+	exporter, closeExporter, err := newExporter(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// ลำดับสำคัญ: ค่าจาก env (OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES) ทับชื่อเริ่มต้น
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(defaultServiceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeExporter != nil {
+			if closeErr := closeExporter(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// newExporter สร้าง exporter ตามชื่อ (none = nil) พร้อมฟังก์ชันปิดไฟล์ของ exporter file
+func newExporter(ctx context.Context, name string) (sdktrace.SpanExporter, func() error, error) {
+	switch name {
+	case ExporterNone:
+		return nil, nil, nil
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case ExporterFile:
+		path := os.Getenv("TRACING_FILE")
+		if path == "" {
+			path = filepath.Join("logs", "traces.jsonl")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, nil, fmt.Errorf("trace file dir: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown TRACING_EXPORTER=%q (want %s, %s, %s or %s)", name, ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile)
+	}
+}
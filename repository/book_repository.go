@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -10,69 +12,151 @@ import (
 )
 
 // BookRepository สัญญาให้ service เรียกใช้งาน
+// error ที่คืนเป็น *apperr.Error เสมอ (ไม่พบ → apperr.NotFound, DB พัง → apperr.Internal)
+// ทุกเมธอดรับ ctx ของ request (request ID ไปถึง SQL comment ผ่าน database.QueryComment)
+// ถ้า ctx มาจาก TxManager.WithinTransaction คำสั่งจะรันใน transaction นั้น
+// ชื่อซ้ำที่หลุดการตรวจมาชน unique index ux_books_title_active จะได้ apperr.Conflict
+// GetAll/GetByID อ่านจาก replica ได้ ส่วนการเขียนและการตรวจชื่อซ้ำไป primary เสมอ
 type BookRepository interface {
-	Create(book *models.Book) error
-	GetAll() ([]models.Book, error)
-	GetByID(bookID uint) (*models.Book, error)
-	Update(book *models.Book) error
-	SoftDelete(bookID uint) error
-	ExistsActiveByTitle(title string) (bool, error)
-	ExistsActiveByTitleExceptID(title string, bookID uint) (bool, error)
+	Create(ctx context.Context, book *models.Book) error
+	GetAll(ctx context.Context) ([]models.Book, error)
+	// ListVersion จำนวนหนังสือที่ยังไม่ถูกลบ + updated_at ล่าสุดของทุกแถว (รวมที่ถูกลบ เพราะการลบก็เลื่อน updated_at)
+	// ใช้ทำ ETag/Last-Modified ของรายการโดยไม่ต้องโหลดทั้งรายการ
+	ListVersion(ctx context.Context) (count int64, lastModified time.Time, err error)
+	GetByID(ctx context.Context, bookID uint) (*models.Book, error)
+	// GetByIDForUpdate เหมือน GetByID แต่ล็อกแถว (SELECT ... FOR UPDATE) ใช้ภายใน TxManager เท่านั้น
+	GetByIDForUpdate(ctx context.Context, bookID uint) (*models.Book, error)
+	// Update แก้เฉพาะแถวที่ยังไม่ถูกลบ — ถูกลบไปแล้ว (หรือไม่มี id นี้) คืน apperr.NotFound ไม่กู้แถวกลับมา
+	Update(ctx context.Context, book *models.Book) error
+	SoftDelete(ctx context.Context, bookID uint) error
+	ExistsActiveByTitle(ctx context.Context, title string) (bool, error)
+	ExistsActiveByTitleExceptID(ctx context.Context, title string, bookID uint) (bool, error)
 }
 
-type bookRepository struct{ db *gorm.DB }
+type bookRepository struct {
+	db     *gorm.DB
+	reader Reader
+}
 
-// NewBookRepository รับ *gorm.DB และคืน Repository ที่พร้อมใช้งาน
-func NewBookRepository(database *gorm.DB) BookRepository { return &bookRepository{db: database} }
+// NewBookRepository รับ *gorm.DB ของ primary และ Reader สำหรับอ่าน (nil = อ่านจาก primary) คืน Repository ที่พร้อมใช้งาน
+func NewBookRepository(database *gorm.DB, reader Reader) BookRepository {
+	return &bookRepository{db: database, reader: reader}
+}
 
-func (repository *bookRepository) Create(book *models.Book) error {
-	return repository.db.Create(book).Error
+func (repository *bookRepository) Create(ctx context.Context, book *models.Book) error {
+	if err := conn(ctx, repository.db).Create(book).Error; err != nil {
+		return dbError("create book", err)
+	}
+	PinPrimary(ctx)
+	return nil
 }
 
-func (repository *bookRepository) GetAll() ([]models.Book, error) {
+func (repository *bookRepository) GetAll(ctx context.Context) ([]models.Book, error) {
 	var books []models.Book
-	err := repository.db.Where("deleted_at IS NULL").Order("id DESC").Find(&books).Error
-	return books, err
+	err := read(ctx, repository.db, repository.reader, func(db *gorm.DB) error {
+		return db.Where("deleted_at IS NULL").Order("id DESC").Find(&books).Error
+	})
+	return books, dbError("list books", err)
+}
+
+func (repository *bookRepository) ListVersion(ctx context.Context) (int64, time.Time, error) {
+	var count int64
+	var latest models.Book
+	err := read(ctx, repository.db, repository.reader, func(db *gorm.DB) error {
+		if err := db.Model(&models.Book{}).Where("deleted_at IS NULL").Count(&count).Error; err != nil {
+			return err
+		}
+		// ORDER BY ... LIMIT 1 แทน MAX() เพราะ SQLite คืน MAX ของ DATETIME เป็นข้อความ
+		err := db.Select("updated_at").Order("updated_at DESC").Take(&latest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			latest = models.Book{}
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return 0, time.Time{}, dbError("book list version", err)
+	}
+	return count, latest.UpdatedAt, nil
+}
+
+func (repository *bookRepository) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	var book models.Book
+	err := read(ctx, repository.db, repository.reader, func(db *gorm.DB) error {
+		return db.Where("id = ? AND deleted_at IS NULL", bookID).First(&book).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errBookNotFound()
+	}
+	if err != nil {
+		return nil, dbError("get book", err)
+	}
+	return &book, nil
 }
 
-func (repository *bookRepository) GetByID(bookID uint) (*models.Book, error) {
+func (repository *bookRepository) GetByIDForUpdate(ctx context.Context, bookID uint) (*models.Book, error) {
 	var book models.Book
-	err := repository.db.Where("id = ? AND deleted_at IS NULL", bookID).First(&book).Error
+	err := conn(ctx, repository.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_at IS NULL", bookID).
+		First(&book).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errBookNotFound()
+	}
 	if err != nil {
-		return nil, err
+		return nil, dbError("get book for update", err)
 	}
 	return &book, nil
 }
 
-func (repository *bookRepository) Update(book *models.Book) error {
+func (repository *bookRepository) Update(ctx context.Context, book *models.Book) error {
 	book.UpdatedAt = time.Now()
-	return repository.db.
-		Session(&gorm.Session{FullSaveAssociations: false}).
+	result := conn(ctx, repository.db).Model(book).
 		Clauses(clause.Returning{}).
-		Save(book).Error
+		Where("deleted_at IS NULL").
+		Updates(map[string]any{"title": book.Title, "author": book.Author, "updated_at": book.UpdatedAt})
+	if result.Error != nil {
+		return dbError("update book", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errBookNotFound()
+	}
+	PinPrimary(ctx)
+	return nil
 }
 
-func (repository *bookRepository) SoftDelete(bookID uint) error {
-	return repository.db.Model(&models.Book{}).
-		Where("id = ?", bookID).
-		Update("deleted_at", gorm.Expr("now()")).Error
+// SoftDelete คืน apperr.NotFound ถ้าไม่มีแถวที่ยังไม่ถูกลบตรงกับ id (ลบซ้ำก็ถือว่าไม่พบ)
+// เลื่อน updated_at ด้วย ให้ ListVersion (ETag/Last-Modified ของรายการ) เปลี่ยนเมื่อมีการลบ
+func (repository *bookRepository) SoftDelete(ctx context.Context, bookID uint) error {
+	now := time.Now()
+	result := conn(ctx, repository.db).Model(&models.Book{}).
+		Where("id = ? AND deleted_at IS NULL", bookID).
+		Updates(map[string]any{"deleted_at": now, "updated_at": now})
+	if result.Error != nil {
+		return dbError("delete book", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errBookNotFound()
+	}
+	PinPrimary(ctx)
+	return nil
 }
 
-// ตรวจชื่อซ้ำ (ไม่แคร์ตัวพิมพ์)
-func (repository *bookRepository) ExistsActiveByTitle(title string) (bool, error) {
+// ตรวจชื่อซ้ำ (ไม่แคร์ตัวพิมพ์) — เงื่อนไขตรงกับ ux_books_title_active จึงใช้ index ได้
+func (repository *bookRepository) ExistsActiveByTitle(ctx context.Context, title string) (bool, error) {
 	normalized := strings.TrimSpace(title)
 	var count int64
-	err := repository.db.Model(&models.Book{}).
-		Where("deleted_at IS NULL AND lower(title)=lower(?)", normalized).
+	err := conn(ctx, repository.db).Model(&models.Book{}).
+		Where("deleted_at IS NULL AND lower(trim(title)) = lower(?)", normalized).
 		Count(&count).Error
-	return count > 0, err
+	return count > 0, dbError("check duplicate title", err)
 }
 
-func (repository *bookRepository) ExistsActiveByTitleExceptID(title string, bookID uint) (bool, error) {
+func (repository *bookRepository) ExistsActiveByTitleExceptID(ctx context.Context, title string, bookID uint) (bool, error) {
 	normalized := strings.TrimSpace(title)
 	var count int64
-	err := repository.db.Model(&models.Book{}).
-		Where("deleted_at IS NULL AND id <> ? AND lower(title)=lower(?)", bookID, normalized).
+	err := conn(ctx, repository.db).Model(&models.Book{}).
+		Where("deleted_at IS NULL AND id <> ? AND lower(trim(title)) = lower(?)", bookID, normalized).
 		Count(&count).Error
-	return count > 0, err
+	return count > 0, dbError("check duplicate title", err)
 }
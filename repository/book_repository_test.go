@@ -0,0 +1,222 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/dbtest"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+func TestMain(m *testing.M) {
+	// ไม่เขียนไฟล์ log ระหว่างเทสต์
+	logger.SetSinks(nil)
+	os.Exit(m.Run())
+}
+
+// TestBookRepository ชุดเดียวกันกับทุก driver (SQLite เสมอ, Postgres/MySQL เมื่อตั้ง TEST_POSTGRES_DSN/TEST_MYSQL_DSN)
+func TestBookRepository(t *testing.T) {
+	dbtest.ForEachDriver(t, func(t *testing.T, handle *database.Handle) {
+		testBookRepository(t, repository.NewBookRepository(handle.DB(), nil), repository.NewTxManager(handle.DB()))
+	})
+}
+
+// TestMemoryBookRepository ตัวในหน่วยความจำต้องผ่านชุดเดียวกับตัว GORM
+func TestMemoryBookRepository(t *testing.T) {
+	testBookRepository(t, repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+}
+
+// testBookRepository สัญญาของ BookRepository ที่ทุก implementation (GORM ทุก driver และในหน่วยความจำ) ต้องทำตาม
+// เริ่มจาก repository ว่าง
+func testBookRepository(t *testing.T, bookRepository repository.BookRepository, transactions repository.TxManager) {
+	ctx := context.Background()
+	create := func(t *testing.T, title string) *models.Book {
+		t.Helper()
+		book := &models.Book{Title: title, Author: "Author"}
+		if err := bookRepository.Create(ctx, book); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		if book.ID == 0 {
+			t.Fatalf("create %q: id not assigned", title)
+		}
+		return book
+	}
+	wantKind := func(t *testing.T, err error, kind apperr.Kind) {
+		t.Helper()
+		if !apperr.IsKind(err, kind) {
+			t.Fatalf("err = %v, want kind %v", err, kind)
+		}
+	}
+
+	// ลำดับของ subtest สำคัญ: ใช้ข้อมูลต่อจากกัน
+	var dune, emma *models.Book
+	t.Run("empty", func(t *testing.T) {
+		books, err := bookRepository.GetAll(ctx)
+		if err != nil || len(books) != 0 {
+			t.Fatalf("GetAll = %v, %v; want empty", books, err)
+		}
+		count, lastModified, err := bookRepository.ListVersion(ctx)
+		if err != nil || count != 0 || !lastModified.IsZero() {
+			t.Fatalf("ListVersion = %d, %v, %v; want 0, zero time", count, lastModified, err)
+		}
+	})
+
+	t.Run("create and get", func(t *testing.T) {
+		dune = create(t, "Dune")
+		emma = create(t, "Emma")
+		got, err := bookRepository.GetByID(ctx, dune.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "Dune" || got.Author != "Author" || got.DeletedAt != nil || got.CreatedAt.IsZero() {
+			t.Errorf("GetByID = %+v", got)
+		}
+		_, err = bookRepository.GetByID(ctx, emma.ID+1000)
+		wantKind(t, err, apperr.NotFound)
+	})
+
+	t.Run("list newest first", func(t *testing.T) {
+		books, err := bookRepository.GetAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(books) != 2 || books[0].ID != emma.ID || books[1].ID != dune.ID {
+			t.Fatalf("GetAll = %+v, want [Emma, Dune]", books)
+		}
+		count, lastModified, err := bookRepository.ListVersion(ctx)
+		if err != nil || count != 2 || lastModified.IsZero() {
+			t.Fatalf("ListVersion = %d, %v, %v; want 2 and a time", count, lastModified, err)
+		}
+	})
+
+	t.Run("title check ignores case and spaces", func(t *testing.T) {
+		exists, err := bookRepository.ExistsActiveByTitle(ctx, "  dUNE ")
+		if err != nil || !exists {
+			t.Errorf("ExistsActiveByTitle = %v, %v; want true", exists, err)
+		}
+		exists, err = bookRepository.ExistsActiveByTitleExceptID(ctx, "dune", dune.ID)
+		if err != nil || exists {
+			t.Errorf("ExistsActiveByTitleExceptID(self) = %v, %v; want false", exists, err)
+		}
+		exists, err = bookRepository.ExistsActiveByTitleExceptID(ctx, "dune", emma.ID)
+		if err != nil || !exists {
+			t.Errorf("ExistsActiveByTitleExceptID(other) = %v, %v; want true", exists, err)
+		}
+	})
+
+	t.Run("duplicate active title is a conflict", func(t *testing.T) {
+		err := bookRepository.Create(ctx, &models.Book{Title: " DUNE", Author: "Other"})
+		wantKind(t, err, apperr.Conflict)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		book, err := bookRepository.GetByIDForUpdate(ctx, emma.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := book.UpdatedAt
+		time.Sleep(10 * time.Millisecond)
+		book.Title, book.Author = "Emma (2nd ed.)", "Jane Austen"
+		if err := bookRepository.Update(ctx, book); err != nil {
+			t.Fatal(err)
+		}
+		got, err := bookRepository.GetByID(ctx, emma.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "Emma (2nd ed.)" || got.Author != "Jane Austen" || !got.UpdatedAt.After(before) {
+			t.Errorf("after update = %+v (updated_at before %v)", got, before)
+		}
+	})
+
+	t.Run("update to another active title is a conflict", func(t *testing.T) {
+		book, err := bookRepository.GetByID(ctx, emma.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		book.Title = "dune"
+		wantKind(t, bookRepository.Update(ctx, book), apperr.Conflict)
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		_, before, err := bookRepository.ListVersion(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if err := bookRepository.SoftDelete(ctx, dune.ID); err != nil {
+			t.Fatal(err)
+		}
+		wantKind(t, bookRepository.SoftDelete(ctx, dune.ID), apperr.NotFound)
+		wantKind(t, bookRepository.SoftDelete(ctx, emma.ID+1000), apperr.NotFound)
+
+		_, err = bookRepository.GetByID(ctx, dune.ID)
+		wantKind(t, err, apperr.NotFound)
+		_, err = bookRepository.GetByIDForUpdate(ctx, dune.ID)
+		wantKind(t, err, apperr.NotFound)
+
+		books, err := bookRepository.GetAll(ctx)
+		if err != nil || len(books) != 1 || books[0].ID != emma.ID {
+			t.Fatalf("GetAll = %+v, %v; want only Emma", books, err)
+		}
+		// การลบก็เปลี่ยนเวอร์ชันของรายการ
+		count, after, err := bookRepository.ListVersion(ctx)
+		if err != nil || count != 1 || !after.After(before) {
+			t.Errorf("ListVersion = %d, %v, %v; want 1 and later than %v", count, after, err, before)
+		}
+	})
+
+	t.Run("update of a soft-deleted book is not found", func(t *testing.T) {
+		// dune ถูกอ่านไว้ก่อนถูกลบ (เหมือน SoftDelete แทรกระหว่าง GetByIDForUpdate กับ Update)
+		stale := *dune
+		stale.Title = "Dune Messiah"
+		wantKind(t, bookRepository.Update(ctx, &stale), apperr.NotFound)
+		_, err := bookRepository.GetByID(ctx, dune.ID)
+		wantKind(t, err, apperr.NotFound)
+
+		missing := models.Book{ID: emma.ID + 1000, Title: "Ghost", Author: "Author"}
+		wantKind(t, bookRepository.Update(ctx, &missing), apperr.NotFound)
+	})
+
+	t.Run("deleted title can be reused", func(t *testing.T) {
+		exists, err := bookRepository.ExistsActiveByTitle(ctx, "Dune")
+		if err != nil || exists {
+			t.Fatalf("ExistsActiveByTitle(deleted) = %v, %v; want false", exists, err)
+		}
+		create(t, "Dune")
+	})
+
+	t.Run("transaction sees its own writes", func(t *testing.T) {
+		err := transactions.WithinTransaction(ctx, func(ctx context.Context) error {
+			book := &models.Book{Title: "Persuasion", Author: "Jane Austen"}
+			if err := bookRepository.Create(ctx, book); err != nil {
+				return err
+			}
+			exists, err := bookRepository.ExistsActiveByTitle(ctx, "persuasion")
+			if err != nil {
+				return err
+			}
+			if !exists {
+				t.Error("title not visible inside the transaction")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := bookRepository.GetAll(cancelled)
+		wantKind(t, err, apperr.Canceled)
+		wantKind(t, bookRepository.Create(cancelled, &models.Book{Title: "Late", Author: "Author"}), apperr.Canceled)
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// SQLSTATE ของ Postgres ที่ต้องแยกชนิด
+const (
+	pgQueryCanceled   = "57014" // ยกเลิกคำสั่ง (เช่น เกิน statement_timeout)
+	pgUniqueViolation = "23505" // ชน unique index (เช่น ux_books_title_active)
+)
+
+// error number ของ MySQL ที่ต้องแยกชนิด (ชน unique index แปลงผ่าน TranslateError เป็น gorm.ErrDuplicatedKey แล้ว)
+const mysqlQueryTimeout = 3024 // เกิน max_execution_time
+
+// errBookNotFound ห่อ gorm.ErrRecordNotFound ไว้ ให้โค้ดเดิมที่เช็ค errors.Is ยังใช้ได้
+func errBookNotFound() error {
+	return apperr.Wrap(apperr.NotFound, "book not found", gorm.ErrRecordNotFound)
+}
+
+// dbError ห่อ error จาก DB เป็น *apperr.Error (nil คืน nil)
+//   - client ตัดการเชื่อมต่อ (context.Canceled) → apperr.Canceled
+//   - เกิน deadline ของ ctx, statement_timeout (postgres) หรือ max_execution_time (mysql) → apperr.Timeout
+//   - ชน unique index → apperr.Conflict
+//   - อื่นๆ → apperr.Internal
+func dbError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	var mysqlErr *mysql.MySQLError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return apperr.Wrap(apperr.Canceled, operation, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperr.Wrap(apperr.Timeout, operation, err)
+	case errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled,
+		errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlQueryTimeout:
+		return apperr.Wrap(apperr.Timeout, operation, err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return apperr.Wrap(apperr.Conflict, operation, err)
+	default:
+		return apperr.Wrap(apperr.Internal, operation, err)
+	}
+}
+
+// isAppError ตรวจว่า err ถูกห่อเป็น *apperr.Error แล้ว (ไม่ต้องห่อซ้ำ)
+func isAppError(err error) bool {
+	var appErr *apperr.Error
+	return errors.As(err, &appErr)
+}
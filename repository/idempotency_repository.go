@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository เก็บ/อ่านผลลัพธ์ของ request ตาม Idempotency-Key
+type IdempotencyRepository interface {
+	// Reserve จองคีย์ (status = 0) ถ้ามีคนจองไว้แล้วจะคืนแถวเดิมกลับมาแทน (reserved = false)
+	Reserve(ctx context.Context, record *models.IdempotencyKey) (existing *models.IdempotencyKey, reserved bool, err error)
+	Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct{ db *gorm.DB }
+
+// NewIdempotencyRepository รับ *gorm.DB และคืน Repository ที่พร้อมใช้งาน
+func NewIdempotencyRepository(database *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: database}
+}
+
+func (repository *idempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	// ลองสองรอบ: รอบแรกอาจชนคีย์ที่หมดอายุแล้ว ลบทิ้งแล้วจองใหม่
+	for attempt := 0; attempt < 2; attempt++ {
+		result := conn(ctx, repository.db).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, false, dbError("reserve idempotency key", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil, true, nil
+		}
+
+		var existing models.IdempotencyKey
+		err := conn(ctx, repository.db).Where("scope = ? AND idempotency_key = ?", record.Scope, record.Key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // ถูกลบไประหว่างทาง ลองจองใหม่
+		}
+		if err != nil {
+			return nil, false, dbError("load idempotency key", err)
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return &existing, false, nil
+		}
+		if err := repository.Release(ctx, record.Scope, record.Key); err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, apperr.New(apperr.Conflict, "idempotency key is being reused concurrently")
+}
+
+func (repository *idempotencyRepository) Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error {
+	err := conn(ctx, repository.db).Model(&models.IdempotencyKey{}).
+		Where("scope = ? AND idempotency_key = ?", scope, key).
+		Updates(map[string]any{"status": status, "content_type": contentType, "response_body": body}).Error
+	return dbError("complete idempotency key", err)
+}
+
+func (repository *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	err := conn(ctx, repository.db).Where("scope = ? AND idempotency_key = ?", scope, key).Delete(&models.IdempotencyKey{}).Error
+	return dbError("release idempotency key", err)
+}
+
+func (repository *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, repository.db).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, dbError("delete expired idempotency keys", result.Error)
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/dbtest"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	dbtest.ForEachDriver(t, func(t *testing.T, handle *database.Handle) {
+		testIdempotencyRepository(t, repository.NewIdempotencyRepository(handle.DB()))
+	})
+}
+
+func TestMemoryIdempotencyRepository(t *testing.T) {
+	testIdempotencyRepository(t, repository.NewMemoryIdempotencyRepository())
+}
+
+// testIdempotencyRepository สัญญาของ IdempotencyRepository ทั้งตัว GORM และในหน่วยความจำ (เริ่มจากว่าง)
+func testIdempotencyRepository(t *testing.T, idempotencyRepository repository.IdempotencyRepository) {
+	ctx := context.Background()
+	now := time.Now()
+	newRecord := func(key string, expiresAt time.Time) *models.IdempotencyKey {
+		return &models.IdempotencyKey{Scope: "POST /books", Key: key, Fingerprint: "fp-" + key, CreatedAt: now, ExpiresAt: expiresAt}
+	}
+
+	t.Run("reserve then replay", func(t *testing.T) {
+		if _, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("a", now.Add(time.Hour))); err != nil || !reserved {
+			t.Fatalf("first Reserve = %v, %v; want reserved", reserved, err)
+		}
+		existing, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("a", now.Add(time.Hour)))
+		if err != nil || reserved || existing == nil || existing.Status != 0 || existing.Fingerprint != "fp-a" {
+			t.Fatalf("second Reserve = %+v, %v, %v; want the in-flight record", existing, reserved, err)
+		}
+
+		if err := idempotencyRepository.Complete(ctx, "POST /books", "a", 201, "application/json", []byte(`{"id":1}`)); err != nil {
+			t.Fatal(err)
+		}
+		existing, reserved, err = idempotencyRepository.Reserve(ctx, newRecord("a", now.Add(time.Hour)))
+		if err != nil || reserved || existing.Status != 201 || existing.ContentType != "application/json" || string(existing.ResponseBody) != `{"id":1}` {
+			t.Fatalf("Reserve after Complete = %+v, %v, %v; want stored response", existing, reserved, err)
+		}
+	})
+
+	t.Run("release frees the key", func(t *testing.T) {
+		if _, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("b", now.Add(time.Hour))); err != nil || !reserved {
+			t.Fatalf("Reserve = %v, %v", reserved, err)
+		}
+		if err := idempotencyRepository.Release(ctx, "POST /books", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if _, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("b", now.Add(time.Hour))); err != nil || !reserved {
+			t.Fatalf("Reserve after Release = %v, %v; want reserved", reserved, err)
+		}
+	})
+
+	t.Run("expired key is reserved again", func(t *testing.T) {
+		if _, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("c", now.Add(-time.Minute))); err != nil || !reserved {
+			t.Fatalf("Reserve = %v, %v", reserved, err)
+		}
+		if _, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("c", now.Add(time.Hour))); err != nil || !reserved {
+			t.Fatalf("Reserve over expired = %v, %v; want reserved", reserved, err)
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		if _, _, err := idempotencyRepository.Reserve(ctx, newRecord("d", now.Add(-time.Minute))); err != nil {
+			t.Fatal(err)
+		}
+		deleted, err := idempotencyRepository.DeleteExpired(ctx, now)
+		if err != nil || deleted != 1 {
+			t.Fatalf("DeleteExpired = %d, %v; want 1", deleted, err)
+		}
+		existing, reserved, err := idempotencyRepository.Reserve(ctx, newRecord("a", now.Add(time.Hour)))
+		if err != nil || reserved || existing.Status != 201 {
+			t.Errorf("live key a = %+v, %v, %v; want kept", existing, reserved, err)
+		}
+	})
+}
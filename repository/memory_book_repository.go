@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// memoryBookRepository BookRepository ในหน่วยความจำ (โหมด --storage=memory และใช้ทดสอบโดยไม่ต้องมี DB)
+// พฤติกรรมเหมือนตัว GORM: soft delete, ตรวจชื่อซ้ำแบบไม่สนตัวพิมพ์, เรียง id DESC
+// และกันชื่อซ้ำตอนเขียนเหมือน unique index ux_books_title_active
+type memoryBookRepository struct {
+	mutex  sync.RWMutex
+	books  map[uint]models.Book
+	nextID uint
+}
+
+// NewMemoryBookRepository คืน BookRepository ว่างๆ ที่ปลอดภัยต่อการเรียกพร้อมกันหลาย goroutine
+func NewMemoryBookRepository() BookRepository {
+	return &memoryBookRepository{books: map[uint]models.Book{}, nextID: 1}
+}
+
+func (repository *memoryBookRepository) Create(ctx context.Context, book *models.Book) error {
+	if err := ctx.Err(); err != nil {
+		return dbError("create book", err)
+	}
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.titleTaken(book.Title, 0) {
+		return errTitleConflict("create book")
+	}
+	now := time.Now()
+	book.ID = repository.nextID
+	repository.nextID++
+	if book.CreatedAt.IsZero() {
+		book.CreatedAt = now
+	}
+	if book.UpdatedAt.IsZero() {
+		book.UpdatedAt = now
+	}
+	repository.books[book.ID] = copyBook(*book)
+	return nil
+}
+
+func (repository *memoryBookRepository) GetAll(ctx context.Context) ([]models.Book, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, dbError("list books", err)
+	}
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	books := make([]models.Book, 0, len(repository.books))
+	for _, book := range repository.books {
+		if book.DeletedAt == nil {
+			books = append(books, copyBook(book))
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID > books[j].ID })
+	return books, nil
+}
+
+func (repository *memoryBookRepository) ListVersion(ctx context.Context) (int64, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, time.Time{}, dbError("count books", err)
+	}
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var count int64
+	var lastModified time.Time
+	for _, book := range repository.books {
+		if book.DeletedAt == nil {
+			count++
+		}
+		if book.UpdatedAt.After(lastModified) {
+			lastModified = book.UpdatedAt
+		}
+	}
+	return count, lastModified, nil
+}
+
+func (repository *memoryBookRepository) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, dbError("get book", err)
+	}
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	book, ok := repository.books[bookID]
+	if !ok || book.DeletedAt != nil {
+		return nil, errBookNotFound()
+	}
+	found := copyBook(book)
+	return &found, nil
+}
+
+// GetByIDForUpdate ไม่ต้องล็อกแถว — memoryTxManager ให้ทำงานทีละ transaction อยู่แล้ว
+func (repository *memoryBookRepository) GetByIDForUpdate(ctx context.Context, bookID uint) (*models.Book, error) {
+	return repository.GetByID(ctx, bookID)
+}
+
+func (repository *memoryBookRepository) Update(ctx context.Context, book *models.Book) error {
+	if err := ctx.Err(); err != nil {
+		return dbError("update book", err)
+	}
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	// ถูกลบไปแล้ว (เช่น SoftDelete ที่ไม่ได้ผ่าน memoryTxManager แทรกเข้ามาหลัง GetByIDForUpdate) = ไม่พบ
+	// ไม่เช่นนั้นจะเขียน DeletedAt == nil ทับ กลายเป็นกู้เล่มที่ลบแล้วกลับมา
+	stored, ok := repository.books[book.ID]
+	if !ok || stored.DeletedAt != nil {
+		return errBookNotFound()
+	}
+	if repository.titleTaken(book.Title, book.ID) {
+		return errTitleConflict("update book")
+	}
+	book.UpdatedAt = time.Now()
+	repository.books[book.ID] = copyBook(*book)
+	return nil
+}
+
+// SoftDelete คืน apperr.NotFound ถ้าไม่มีแถวที่ยังไม่ถูกลบตรงกับ id (ลบซ้ำก็ถือว่าไม่พบ)
+func (repository *memoryBookRepository) SoftDelete(ctx context.Context, bookID uint) error {
+	if err := ctx.Err(); err != nil {
+		return dbError("delete book", err)
+	}
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	book, ok := repository.books[bookID]
+	if !ok || book.DeletedAt != nil {
+		return errBookNotFound()
+	}
+	now := time.Now()
+	book.DeletedAt = &now
+	book.UpdatedAt = now
+	repository.books[bookID] = book
+	return nil
+}
+
+func (repository *memoryBookRepository) ExistsActiveByTitle(ctx context.Context, title string) (bool, error) {
+	return repository.ExistsActiveByTitleExceptID(ctx, title, 0)
+}
+
+func (repository *memoryBookRepository) ExistsActiveByTitleExceptID(ctx context.Context, title string, bookID uint) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, dbError("check duplicate title", err)
+	}
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+	return repository.titleTaken(title, bookID), nil
+}
+
+// titleTaken เงื่อนไขเดียวกับ lower(trim(title)) ของ unique index (ผู้เรียกต้องถือ mutex อยู่)
+func (repository *memoryBookRepository) titleTaken(title string, exceptID uint) bool {
+	normalized := strings.ToLower(strings.TrimSpace(title))
+	for id, book := range repository.books {
+		if id != exceptID && book.DeletedAt == nil && strings.ToLower(strings.TrimSpace(book.Title)) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// errTitleConflict error เดียวกับที่ dbError ให้เมื่อชน unique index
+func errTitleConflict(operation string) error {
+	return apperr.New(apperr.Conflict, operation+": duplicate active title")
+}
+
+// copyBook คัดลอก DeletedAt ด้วย ผู้เรียกจะได้แก้ค่าที่คืนไปโดยไม่กระทบข้อมูลที่เก็บไว้
+func copyBook(book models.Book) models.Book {
+	if book.DeletedAt != nil {
+		deletedAt := *book.DeletedAt
+		book.DeletedAt = &deletedAt
+	}
+	return book
+}
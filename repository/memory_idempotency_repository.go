@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+)
+
+type idempotencyMapKey struct{ scope, key string }
+
+// memoryIdempotencyRepository IdempotencyRepository ในหน่วยความจำ (คู่กับ NewMemoryBookRepository)
+type memoryIdempotencyRepository struct {
+	mutex   sync.Mutex
+	records map[idempotencyMapKey]models.IdempotencyKey
+}
+
+// NewMemoryIdempotencyRepository คืน IdempotencyRepository ว่างๆ ที่ปลอดภัยต่อการเรียกพร้อมกัน
+func NewMemoryIdempotencyRepository() IdempotencyRepository {
+	return &memoryIdempotencyRepository{records: map[idempotencyMapKey]models.IdempotencyKey{}}
+}
+
+func (repository *memoryIdempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, dbError("reserve idempotency key", err)
+	}
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	mapKey := idempotencyMapKey{record.Scope, record.Key}
+	if existing, ok := repository.records[mapKey]; ok && existing.ExpiresAt.After(time.Now()) {
+		existing.ResponseBody = append([]byte(nil), existing.ResponseBody...)
+		return &existing, false, nil
+	}
+	repository.records[mapKey] = *record
+	return nil, true, nil
+}
+
+func (repository *memoryIdempotencyRepository) Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	mapKey := idempotencyMapKey{scope, key}
+	record, ok := repository.records[mapKey]
+	if !ok {
+		return nil
+	}
+	record.Status = status
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	repository.records[mapKey] = record
+	return nil
+}
+
+func (repository *memoryIdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	delete(repository.records, idempotencyMapKey{scope, key})
+	return nil
+}
+
+func (repository *memoryIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var deleted int64
+	for mapKey, record := range repository.records {
+		if !record.ExpiresAt.After(now) {
+			delete(repository.records, mapKey)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// Reader เลือก *gorm.DB สำหรับอ่าน — database.Handle คืน replica ที่ healthy หรือ primary ถ้าไม่มี
+type Reader interface {
+	Reader() *gorm.DB
+	// ReplicaFailed รับ error ของการอ่านผ่าน db ที่ Reader คืนมา คืน true ถ้าเป็น replica ที่ล้มระดับ connection
+	// (replica นั้นถูกทำเป็น unhealthy แล้ว) ให้อ่านซ้ำที่ primary
+	ReplicaFailed(db *gorm.DB, err error) bool
+}
+
+type primaryPinKey struct{}
+
+// WithReadYourWrites เตรียม ctx ของ request ให้ปักหมุดไป primary ได้ (เรียกครั้งเดียวต่อ request ใน middleware)
+// หลังจากเขียนสำเร็จ การอ่านที่เหลือของ request เดียวกันจะไป primary ไม่เห็นข้อมูลเก่าจาก replica ที่ตามไม่ทัน
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryPinKey{}, new(atomic.Bool))
+}
+
+// PinPrimary ให้การอ่านที่เหลือของ request นี้ไป primary (repository เรียกเองหลังเขียนสำเร็จ)
+// ctx ที่ไม่ได้ผ่าน WithReadYourWrites จะไม่มีผล
+func PinPrimary(ctx context.Context) {
+	if pin, ok := ctx.Value(primaryPinKey{}).(*atomic.Bool); ok {
+		pin.Store(true)
+	}
+}
+
+func pinnedToPrimary(ctx context.Context) bool {
+	pin, ok := ctx.Value(primaryPinKey{}).(*atomic.Bool)
+	return ok && pin.Load()
+}
+
+// read รัน query บน db สำหรับอ่าน: อยู่ใน transaction หรือถูกปักหมุด → primary นอกนั้นไป replica
+// replica ล้มระดับ connection ระหว่าง query → รันซ้ำที่ primary (request นี้ไม่ล้ม) และ request ถัดไปไม่ถูกส่งไป replica นั้น
+func read(ctx context.Context, primary *gorm.DB, reader Reader, query func(db *gorm.DB) error) error {
+	if _, inTransaction := ctx.Value(txContextKey{}).(*gorm.DB); inTransaction || reader == nil || pinnedToPrimary(ctx) {
+		return query(conn(ctx, primary))
+	}
+	db := reader.Reader()
+	err := query(db.WithContext(ctx))
+	if err != nil && ctx.Err() == nil && reader.ReplicaFailed(db, err) {
+		return query(conn(ctx, primary))
+	}
+	return err
+}
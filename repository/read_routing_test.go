@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database/dbtest"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+)
+
+// downPool pool ของ replica ที่ต่อไม่ได้: ทุก query คืน driver.ErrBadConn
+type downPool struct {
+	*sql.DB
+}
+
+func (downPool) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, driver.ErrBadConn
+}
+
+// fakeReader ส่งทุกการอ่านไป replica ที่ล่มจนกว่าจะถูกแจ้งว่าล้ม
+type fakeReader struct {
+	primary, replica *gorm.DB
+	failed           int
+}
+
+func (reader *fakeReader) Reader() *gorm.DB {
+	if reader.failed > 0 {
+		return reader.primary
+	}
+	return reader.replica
+}
+
+func (reader *fakeReader) ReplicaFailed(db *gorm.DB, err error) bool {
+	if db != reader.replica {
+		return false
+	}
+	reader.failed++
+	return true
+}
+
+func TestReadsFailOverToPrimaryWhenReplicaIsDown(t *testing.T) {
+	handle := dbtest.OpenSQLite(t)
+	sqlDB, err := handle.DB().DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, err := gorm.Open(sqlite.Dialector{Conn: downPool{sqlDB}}, &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := &fakeReader{primary: handle.DB(), replica: replica}
+	bookRepository := repository.NewBookRepository(handle.DB(), reader)
+
+	ctx := context.Background()
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := bookRepository.Create(ctx, book); err != nil {
+		t.Fatal(err)
+	}
+
+	// request ที่เจอ replica ล่มยังได้ผลจาก primary
+	got, err := bookRepository.GetByID(ctx, book.ID)
+	if err != nil || got.Title != "Dune" {
+		t.Fatalf("GetByID = %+v, %v; want Dune from primary", got, err)
+	}
+	if reader.failed != 1 {
+		t.Fatalf("ReplicaFailed calls = %d, want 1", reader.failed)
+	}
+	// request ถัดไป Reader ไม่คืน replica ที่ล่มแล้ว
+	books, err := bookRepository.GetAll(ctx)
+	if err != nil || len(books) != 1 {
+		t.Fatalf("GetAll = %+v, %v; want 1 book", books, err)
+	}
+	count, _, err := bookRepository.ListVersion(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("ListVersion count = %d, %v; want 1", count, err)
+	}
+	if reader.failed != 1 {
+		t.Errorf("ReplicaFailed calls = %d, want still 1", reader.failed)
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// TxManager รันหลายคำสั่งของ repository ใน transaction เดียว (unit of work)
+// repository ทุกตัวที่ได้ ctx จาก fn จะใช้ transaction เดียวกันโดยอัตโนมัติ
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txContextKey struct{}
+
+type gormTxManager struct{ db *gorm.DB }
+
+// NewTxManager รับ *gorm.DB และคืน TxManager ที่พร้อมใช้งาน
+func NewTxManager(database *gorm.DB) TxManager { return &gormTxManager{db: database} }
+
+// WithinTransaction commit เมื่อ fn คืน nil, rollback เมื่อคืน error หรือ panic
+// ถ้า ctx อยู่ใน transaction อยู่แล้วจะใช้ตัวเดิม (ไม่เปิดซ้อน)
+func (manager *gormTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	err := manager.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+	// fn คืน *apperr.Error มาอยู่แล้ว ส่วน error จาก BEGIN/COMMIT ห่อให้เป็นชนิดเดียวกัน
+	if err != nil && !isAppError(err) {
+		return dbError("transaction", err)
+	}
+	return err
+}
+
+// conn คืน *gorm.DB ที่ผูกกับ ctx: ถ้าอยู่ใน WithinTransaction ใช้ tx นั้น ไม่เช่นนั้นใช้ db ปกติ
+func conn(ctx context.Context, database *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return database.WithContext(ctx)
+}
+
+type memoryTxContextKey struct{}
+
+// memoryTxManager TxManager คู่กับ repository ในหน่วยความจำ
+// ให้ทำงานทีละ unit of work (เหมือน SERIALIZABLE) แต่ไม่มี rollback — service เขียนแค่ครั้งเดียวต่อ transaction อยู่แล้ว
+type memoryTxManager struct{ mutex sync.Mutex }
+
+// NewMemoryTxManager คืน TxManager สำหรับโหมด --storage=memory
+func NewMemoryTxManager() TxManager { return &memoryTxManager{} }
+
+func (manager *memoryTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxContextKey{}) != nil {
+		return fn(ctx)
+	}
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	return fn(context.WithValue(ctx, memoryTxContextKey{}, true))
+}
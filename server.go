@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/health"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+// newHTTPServer สร้าง http.Server พร้อม timeout จาก env (ค่าที่ไม่ตั้งใช้ค่าเริ่มต้นในวงเล็บ)
+//
+//	HTTP_READ_TIMEOUT (15s)        อ่าน request ทั้งก้อน (header + body)
+//	HTTP_READ_HEADER_TIMEOUT (5s)  อ่าน header (กัน slowloris)
+//	HTTP_WRITE_TIMEOUT (30s)       เขียน response — ควรมากกว่า ROUTE_WRITE_TIMEOUT
+//	HTTP_IDLE_TIMEOUT (60s)        keep-alive connection ว่าง
+//	HTTP_MAX_HEADER_BYTES (1MiB)   ขนาด header สูงสุด
+//
+// ค่า 0 ของ timeout = ไม่จำกัด
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       durationFromEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: durationFromEnv("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      durationFromEnv("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       durationFromEnv("HTTP_IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:    intFromEnv("HTTP_MAX_HEADER_BYTES", 1<<20),
+	}
+}
+
+// backgroundJobs goroutine เบื้องหลังที่ต้องหยุดและรอให้จบก่อนปิด DB
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wait   sync.WaitGroup
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{ctx: ctx, cancel: cancel}
+}
+
+// Go เริ่ม job — job ต้องคืนเมื่อ ctx ถูกยกเลิก
+func (jobs *backgroundJobs) Go(job func(ctx context.Context)) {
+	jobs.wait.Add(1)
+	go func() {
+		defer jobs.wait.Done()
+		job(jobs.ctx)
+	}()
+}
+
+// Stop ยกเลิกทุก job แล้วรอจนจบ (ไม่เกินเวลาของ ctx)
+func (jobs *backgroundJobs) Stop(ctx context.Context) error {
+	jobs.cancel()
+	done := make(chan struct{})
+	go func() {
+		jobs.wait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background jobs: %w", ctx.Err())
+	}
+}
+
+// serve เปิด server จน ctx ถูกยกเลิก (SIGINT/SIGTERM) แล้วปิดตามลำดับ:
+//  1. /readyz ตอบ 503 แล้วรอ SHUTDOWN_DRAIN_DELAY (0s) ให้ load balancer เห็นก่อน
+//  2. server.Shutdown: หยุดรับ connection ใหม่ รอ request ที่ค้างจนเสร็จ
+//  3. หยุด background job
+//
+// ข้อ 2–3 รวมกันไม่เกิน SHUTDOWN_TIMEOUT (30s) — ปิด DB และ flush log ทำต่อใน defer ของ run
+func serve(ctx context.Context, server *http.Server, readiness *health.Readiness, jobs *backgroundJobs) error {
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("http server listening on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		// เปิด port ไม่ได้ ฯลฯ — ยังต้องหยุด job ก่อนคืน
+		_ = jobs.Stop(context.Background())
+		return fmt.Errorf("http server: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Print("shutdown: signal received, draining requests")
+	// stream ที่ไม่จบเอง (tail ของ /admin/logs/tail) ต้องถูกปิด ไม่งั้น Shutdown รอจนหมดเวลา
+	server.RegisterOnShutdown(logger.CloseSubscribers)
+	readiness.StartShutdown()
+	if delay := durationFromEnv("SHUTDOWN_DRAIN_DELAY", 0); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	shutdownContext, cancel := context.WithTimeout(context.Background(), durationFromEnv("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+
+	var shutdownErr error
+	if err := server.Shutdown(shutdownContext); err != nil {
+		// หมดเวลา — ตัด connection ที่เหลือทิ้ง
+		shutdownErr = fmt.Errorf("http server shutdown: %w", err)
+		_ = server.Close()
+	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+	if err := jobs.Stop(shutdownContext); err != nil {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+	if shutdownErr == nil {
+		log.Print("shutdown: complete")
+	}
+	return shutdownErr
+}
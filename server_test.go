@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/health"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.SetSinks(nil)
+	os.Exit(m.Run())
+}
+
+// events บันทึกลำดับเหตุการณ์จากหลาย goroutine
+type events struct {
+	mutex sync.Mutex
+	names []string
+	times map[string]time.Time
+}
+
+func (recorded *events) add(name string) {
+	recorded.mutex.Lock()
+	defer recorded.mutex.Unlock()
+	recorded.names = append(recorded.names, name)
+	recorded.times[name] = time.Now()
+}
+
+func (recorded *events) snapshot() ([]string, map[string]time.Time) {
+	recorded.mutex.Lock()
+	defer recorded.mutex.Unlock()
+	return slices.Clone(recorded.names), recorded.times
+}
+
+// freeAddress port ว่างบน loopback (ปิด listener ทันที ให้ serve เปิดเอง)
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// ctx ถูกยกเลิกระหว่างมี request ค้าง: /readyz ตอบ 503 → รอ drain delay → Shutdown (ปิด subscriber, รอ request จบ) → หยุด job
+func TestServeShutdownOrder(t *testing.T) {
+	const drainDelay = 150 * time.Millisecond
+	t.Setenv("SHUTDOWN_DRAIN_DELAY", drainDelay.String())
+	t.Setenv("SHUTDOWN_TIMEOUT", "5s")
+
+	recorded := &events{times: map[string]time.Time{}}
+	readiness := health.NewReadiness()
+	registry := health.NewRegistry(readiness, 0, time.Second)
+	started := make(chan struct{})
+
+	engine := gin.New()
+	engine.GET("/readyz", health.ReadinessHandler(registry))
+	engine.GET("/slow", func(c *gin.Context) {
+		close(started)
+		// นานกว่า drain delay: ต้องเสร็จระหว่าง Shutdown ไม่ถูกตัด
+		time.Sleep(2 * drainDelay)
+		recorded.add("request finished")
+		c.String(http.StatusOK, "done")
+	})
+
+	jobs := newBackgroundJobs()
+	jobs.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		recorded.add("jobs stopped")
+	})
+	subscription := logger.Subscribe(1)
+	go func() {
+		for range subscription.Records {
+		}
+		recorded.add("subscribers closed")
+	}()
+
+	address := freeAddress(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newHTTPServer(address, engine), readiness, jobs) }()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	readyStatus := func() int {
+		response, err := client.Get("http://" + address + "/readyz")
+		if err != nil {
+			return 0
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for readyStatus() != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("server never became ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		response, err := client.Get("http://" + address + "/slow")
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		inFlight <- result{status: response.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	cancel()
+	recorded.add("signal")
+	// ระหว่าง drain delay server ยังรับ connection ใหม่ แต่ /readyz ตอบ 503
+	for status := readyStatus(); status != http.StatusServiceUnavailable; status = readyStatus() {
+		if status == 0 || time.Now().After(deadline) {
+			t.Fatalf("/readyz status = %d during drain, want 503 while still accepting connections", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	recorded.add("readiness failing")
+
+	if got := <-inFlight; got.err != nil || got.status != http.StatusOK || got.body != "done" {
+		t.Fatalf("in-flight request = %+v, want 200 done", got)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+
+	names, times := recorded.snapshot()
+	want := []string{"signal", "readiness failing", "subscribers closed", "request finished", "jobs stopped"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("events = %q, want %q", names, want)
+	}
+	if waited := times["subscribers closed"].Sub(times["signal"]); waited < drainDelay {
+		t.Errorf("Shutdown began %s after the signal, want at least the drain delay %s", waited, drainDelay)
+	}
+	if readyStatus() != 0 {
+		t.Error("server still accepts connections after serve returned")
+	}
+}
@@ -0,0 +1,75 @@
+// Package apperr error ที่มีชนิด (Kind) ใช้ร่วมกันระหว่าง repository, service และ handler
+// repository เป็นคนสร้าง (เช่น NotFound), service เพิ่ม error ธุรกิจ, handler แปลง Kind เป็นสถานะ HTTP
+package apperr
+
+import "errors"
+
+// Kind ชนิดของ error ที่ handler ใช้ตัดสินใจสถานะ HTTP
+type Kind uint8
+
+const (
+	Internal   Kind = iota // 500 — error ที่ไม่คาดคิด (DB ล่ม ฯลฯ)
+	NotFound               // 404 — ไม่พบข้อมูล
+	Conflict               // 409 — ข้อมูลชนกัน (เช่น ชื่อซ้ำ)
+	Validation             // 400 — input ไม่ถูกต้อง
+	Canceled               // 499 — client ตัดการเชื่อมต่อก่อนได้คำตอบ
+	Timeout                // 504 — เกินเวลาที่กำหนด (route timeout หรือ statement_timeout)
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case NotFound:
+		return "not_found"
+	case Conflict:
+		return "conflict"
+	case Validation:
+		return "validation"
+	case Canceled:
+		return "canceled"
+	case Timeout:
+		return "timeout"
+	default:
+		return "internal"
+	}
+}
+
+// Error error ที่มี Kind + ข้อความ + สาเหตุเดิม (ถ้ามี)
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+// New สร้าง error ที่ไม่มีสาเหตุภายใน (เหมาะกับ sentinel เช่น service.ErrTitleExists)
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap ห่อ error เดิมไว้ (errors.Is/As ยังมองเห็นสาเหตุเดิม เช่น gorm.ErrRecordNotFound)
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func (appErr *Error) Error() string {
+	if appErr.Err != nil {
+		return appErr.Message + ": " + appErr.Err.Error()
+	}
+	return appErr.Message
+}
+
+func (appErr *Error) Unwrap() error { return appErr.Err }
+
+// KindOf คืน Kind ของ error ตัวนอกสุดในสาย wrap ที่เป็น *Error
+// error อื่นที่ไม่รู้จักถือเป็น Internal
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return Internal
+}
+
+// IsKind ย่อจาก err != nil && KindOf(err) == kind
+func IsKind(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
@@ -1,39 +1,71 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nuba55yo/go-101-BasicCRUD/dto"
 	"github.com/nuba55yo/go-101-BasicCRUD/models"
 	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/metrics"
 	"github.com/nuba55yo/go-101-BasicCRUD/repository"
-	"gorm.io/gorm"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
 )
 
 // error ธุรกิจที่ handler จะใช้ตัดสินใจแปลงเป็นสถานะ HTTP
+// ทุกตัวเป็น *apperr.Error — handler ดู apperr.KindOf(err) ได้โดยไม่ต้องรู้จักทุก sentinel
 var (
-	ErrTitleExists = errors.New("title already exists")
-	ErrBadInput    = errors.New("invalid input")
+	ErrTitleExists = apperr.New(apperr.Conflict, "title already exists")
+	ErrBadInput    = apperr.New(apperr.Validation, "invalid input")
 )
 
 // BookService กำหนดสัญญาให้เลเยอร์บนเรียกใช้งาน
+// ctx มาจาก c.Request.Context() — พา request ID ไปถึง log และ SQL
 type BookService interface {
-	Create(dto.CreateBookRequest) (*models.Book, error)
-	GetAll() ([]models.Book, error)
-	GetByID(bookID uint) (*models.Book, error)
-	Update(bookID uint, request dto.UpdateBookRequest) (*models.Book, error)
-	Delete(bookID uint) error
+	Create(ctx context.Context, request dto.CreateBookRequest) (*models.Book, error)
+	GetAll(ctx context.Context) ([]models.Book, error)
+	// ListVersion ตัวบอกเวอร์ชันของรายการ (สำหรับ ETag/Last-Modified) ถูกกว่าการโหลด GetAll มาก
+	ListVersion(ctx context.Context) (ListVersion, error)
+	GetByID(ctx context.Context, bookID uint) (*models.Book, error)
+	Update(ctx context.Context, bookID uint, request dto.UpdateBookRequest) (*models.Book, error)
+	Delete(ctx context.Context, bookID uint) error
+}
+
+// ListVersion เปลี่ยนทุกครั้งที่มีการสร้าง/แก้ไข/ลบหนังสือ
+type ListVersion struct {
+	Count        int64     // จำนวนหนังสือที่ยังไม่ถูกลบ
+	LastModified time.Time // updated_at ล่าสุด (zero = ยังไม่มีหนังสือเลย)
 }
 
 // bookService โครงสร้างภายใน (ซ่อนหลัง interface) — หลีกเลี่ยงใช้ตัวอักษรเดียว
 type bookService struct {
-	repository repository.BookRepository
+	repository   repository.BookRepository
+	transactions repository.TxManager
 }
 
-// NewBookService คืน service พร้อม repository ที่ถูกฉีดเข้ามา
-func NewBookService(bookRepository repository.BookRepository) BookService {
-	return &bookService{repository: bookRepository}
+// NewBookService คืน service พร้อม repository และ TxManager ที่ถูกฉีดเข้ามา
+// Create/Update ตรวจชื่อซ้ำแล้วเขียนใน transaction เดียวกันผ่าน TxManager
+func NewBookService(bookRepository repository.BookRepository, txManager repository.TxManager) BookService {
+	return &bookService{repository: bookRepository, transactions: txManager}
+}
+
+// asTitleConflict ชื่อซ้ำที่ชน unique index (สอง request ผ่านการตรวจพร้อมกัน) → ErrTitleExists
+func asTitleConflict(err error) error {
+	if apperr.IsKind(err, apperr.Conflict) && !errors.Is(err, ErrTitleExists) {
+		return ErrTitleExists
+	}
+	return err
+}
+
+// countTitleConflict นับการปฏิเสธเพราะชื่อซ้ำลง metrics แล้วคืน err เดิม
+func countTitleConflict(operation string, err error) error {
+	if errors.Is(err, ErrTitleExists) {
+		metrics.TitleConflict(operation)
+	}
+	return err
 }
 
 // normalize ตัดช่องว่างหัว-ท้าย เพื่อกันเคสส่ง "  ชื่อ  "
@@ -41,89 +73,127 @@ func normalize(title, author string) (string, string) {
 	return strings.TrimSpace(title), strings.TrimSpace(author)
 }
 
-func (serviceImpl *bookService) Create(request dto.CreateBookRequest) (*models.Book, error) {
+// logFailure เลือกระดับ log ตามชนิด error: ไม่พบ/ชนกัน → ไม่ต้อง log (handler ตอบ 404/409 และ access log บันทึกแล้ว),
+// client ยกเลิก/หมดเวลา → warn แยกข้อความให้เห็นชัด, ที่เหลือ → error
+func logFailure(ctx context.Context, err error, format string, a ...any) {
+	message := fmt.Sprintf(format, a...)
+	switch apperr.KindOf(err) {
+	case apperr.NotFound, apperr.Conflict:
+		return
+	case apperr.Canceled:
+		logger.WarnfContext(ctx, "books", "cancelled by client: %s: %v", message, err)
+	case apperr.Timeout:
+		logger.WarnfContext(ctx, "books", "timed out: %s: %v", message, err)
+	default:
+		logger.ErrorfContext(ctx, "books", "%s: %v", message, err)
+	}
+}
+
+func (serviceImpl *bookService) Create(ctx context.Context, request dto.CreateBookRequest) (*models.Book, error) {
 	title, author := normalize(request.Title, request.Author)
 	if title == "" || author == "" {
 		return nil, ErrBadInput
 	}
 
-	// ตรวจชื่อซ้ำ (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
-	exists, err := serviceImpl.repository.ExistsActiveByTitle(title)
-	if err != nil {
-		logger.Errorf("books", "check duplicate failed: %v", err)
-		return nil, err
-	}
-	if exists {
-		return nil, ErrTitleExists
-	}
-
 	newBook := &models.Book{Title: title, Author: author}
-	if err := serviceImpl.repository.Create(newBook); err != nil {
-		logger.Errorf("books", "create failed: %v", err)
-		return nil, err
+	err := serviceImpl.transactions.WithinTransaction(ctx, func(ctx context.Context) error {
+		// ตรวจชื่อซ้ำ (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+		exists, err := serviceImpl.repository.ExistsActiveByTitle(ctx, title)
+		if err != nil {
+			logFailure(ctx, err, "check duplicate failed")
+			return err
+		}
+		if exists {
+			return ErrTitleExists
+		}
+
+		if err := serviceImpl.repository.Create(ctx, newBook); err != nil {
+			logFailure(ctx, err, "create failed")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, countTitleConflict("create", asTitleConflict(err))
 	}
 
-	logger.Infof("books", "created id=%d title=%s", newBook.ID, newBook.Title)
+	metrics.BookCreated()
+	logger.InfoContext(ctx, "books", "created", logger.Uint("id", newBook.ID), logger.String("title", newBook.Title))
 	return newBook, nil
 }
 
-func (serviceImpl *bookService) GetAll() ([]models.Book, error) {
-	books, err := serviceImpl.repository.GetAll()
+func (serviceImpl *bookService) GetAll(ctx context.Context) ([]models.Book, error) {
+	books, err := serviceImpl.repository.GetAll(ctx)
 	if err != nil {
-		logger.Errorf("books", "list failed: %v", err)
+		logFailure(ctx, err, "list failed")
 	}
 	return books, err
 }
 
-func (serviceImpl *bookService) GetByID(bookID uint) (*models.Book, error) {
-	book, err := serviceImpl.repository.GetByID(bookID)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
+func (serviceImpl *bookService) ListVersion(ctx context.Context) (ListVersion, error) {
+	count, lastModified, err := serviceImpl.repository.ListVersion(ctx)
+	if err != nil {
+		logFailure(ctx, err, "list version failed")
 	}
+	return ListVersion{Count: count, LastModified: lastModified}, err
+}
+
+func (serviceImpl *bookService) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	book, err := serviceImpl.repository.GetByID(ctx, bookID)
 	if err != nil {
-		logger.Errorf("books", "get failed: %v", err)
+		logFailure(ctx, err, "get failed")
 	}
 	return book, err
 }
 
-func (serviceImpl *bookService) Update(bookID uint, request dto.UpdateBookRequest) (*models.Book, error) {
+func (serviceImpl *bookService) Update(ctx context.Context, bookID uint, request dto.UpdateBookRequest) (*models.Book, error) {
 	title, author := normalize(request.Title, request.Author)
 	if title == "" || author == "" {
 		return nil, ErrBadInput
 	}
 
-	book, err := serviceImpl.repository.GetByID(bookID)
+	var book *models.Book
+	err := serviceImpl.transactions.WithinTransaction(ctx, func(ctx context.Context) error {
+		// ล็อกแถวไว้จนจบ transaction กันสอง request แก้เล่มเดียวกันทับกัน
+		var err error
+		book, err = serviceImpl.repository.GetByIDForUpdate(ctx, bookID)
+		if err != nil {
+			logFailure(ctx, err, "get before update failed id=%d", bookID)
+			return err
+		}
+
+		// ตรวจชื่อซ้ำ ยกเว้นเล่มตัวเอง
+		exists, err := serviceImpl.repository.ExistsActiveByTitleExceptID(ctx, title, bookID)
+		if err != nil {
+			logFailure(ctx, err, "check duplicate failed")
+			return err
+		}
+		if exists {
+			return ErrTitleExists
+		}
+
+		book.Title = title
+		book.Author = author
+
+		if err := serviceImpl.repository.Update(ctx, book); err != nil {
+			logFailure(ctx, err, "update failed")
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, err
-	}
-
-	// ตรวจชื่อซ้ำ ยกเว้นเล่มตัวเอง
-	exists, err := serviceImpl.repository.ExistsActiveByTitleExceptID(title, bookID)
-	if err != nil {
-		logger.Errorf("books", "check duplicate failed: %v", err)
-		return nil, err
-	}
-	if exists {
-		return nil, ErrTitleExists
-	}
-
-	book.Title = title
-	book.Author = author
-
-	if err := serviceImpl.repository.Update(book); err != nil {
-		logger.Errorf("books", "update failed: %v", err)
-		return nil, err
+		return nil, countTitleConflict("update", asTitleConflict(err))
 	}
 
-	logger.Infof("books", "updated id=%d title=%s", book.ID, book.Title)
+	logger.InfoContext(ctx, "books", "updated", logger.Uint("id", book.ID), logger.String("title", book.Title))
 	return book, nil
 }
 
-func (serviceImpl *bookService) Delete(bookID uint) error {
-	if err := serviceImpl.repository.SoftDelete(bookID); err != nil {
-		logger.Errorf("books", "delete failed id=%d: %v", bookID, err)
+func (serviceImpl *bookService) Delete(ctx context.Context, bookID uint) error {
+	if err := serviceImpl.repository.SoftDelete(ctx, bookID); err != nil {
+		logFailure(ctx, err, "delete failed id=%d", bookID)
 		return err
 	}
-	logger.Infof("books", "deleted id=%d", bookID)
+	logger.InfoContext(ctx, "books", "deleted", logger.Uint("id", bookID))
 	return nil
 }
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database"
+	"github.com/nuba55yo/go-101-BasicCRUD/database/dbtest"
+	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+)
+
+func TestMain(m *testing.M) {
+	// ไม่เขียนไฟล์ log ระหว่างเทสต์
+	logger.SetSinks(nil)
+	os.Exit(m.Run())
+}
+
+// skipTitleCheck ข้ามการตรวจชื่อซ้ำใน transaction — เหลือแต่ unique index ux_books_title_active ที่กันไว้
+type skipTitleCheck struct{ repository.BookRepository }
+
+func (skipTitleCheck) ExistsActiveByTitle(context.Context, string) (bool, error) { return false, nil }
+
+// ยิง Create ชื่อเดียวกันพร้อมกัน: ต้องสำเร็จเล่มเดียว ที่เหลือได้ ErrTitleExists (ไม่ใช่ 500)
+// ทั้งกรณีชนตอนตรวจใน transaction และกรณีหลุดไปชน unique index — กับทุก driver (แต่ละ driver ตอบ error ชนกันต่างกัน)
+func TestCreateConcurrentSameTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(repository.BookRepository) repository.BookRepository
+	}{
+		{name: "checked in transaction", wrap: func(bookRepository repository.BookRepository) repository.BookRepository { return bookRepository }},
+		{name: "unique index only", wrap: func(bookRepository repository.BookRepository) repository.BookRepository {
+			return skipTitleCheck{bookRepository}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dbtest.ForEachDriver(t, func(t *testing.T, handle *database.Handle) {
+				bookRepository := test.wrap(repository.NewBookRepository(handle.DB(), nil))
+				assertSingleCreate(t, service.NewBookService(bookRepository, repository.NewTxManager(handle.DB())))
+			})
+		})
+	}
+}
+
+func assertSingleCreate(t *testing.T, bookService service.BookService) {
+	t.Helper()
+	const workers = 20
+	var (
+		start     sync.WaitGroup
+		done      sync.WaitGroup
+		mutex     sync.Mutex
+		succeeded int
+		failures  []error
+	)
+	start.Add(1)
+	for worker := 0; worker < workers; worker++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			// ตัวพิมพ์/ช่องว่างต่างกันก็ถือว่าชื่อเดียวกัน
+			title := "Dune"
+			if worker%2 == 1 {
+				title = " dune "
+			}
+			_, err := bookService.Create(context.Background(), dto.CreateBookRequest{Title: title, Author: "Frank Herbert"})
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err == nil {
+				succeeded++
+			} else {
+				failures = append(failures, err)
+			}
+		}()
+	}
+	start.Done()
+	done.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("succeeded = %d, want exactly 1 (failures: %v)", succeeded, failures)
+	}
+	for _, err := range failures {
+		if !errors.Is(err, service.ErrTitleExists) {
+			t.Errorf("err = %v, want ErrTitleExists", err)
+		}
+	}
+	books, err := bookService.GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 {
+		t.Errorf("stored %d books, want 1", len(books))
+	}
+}
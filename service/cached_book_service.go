@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/cache"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/logger"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// key ใน cache
+const (
+	bookCacheListKey   = "books:list"
+	bookCacheKeyPrefix = "books:id:"
+)
+
+// cacheLoadTimeout เวลาสูงสุดของการโหลดจากแหล่งจริงที่หลาย request รอร่วมกัน
+// (ไม่ผูกกับ ctx ของ request ใด จึงต้องมีขอบเขตของตัวเอง ไม่ให้ค้างตลอดไปเมื่อ DB ไม่ตอบ)
+const cacheLoadTimeout = 15 * time.Second
+
+// CacheStats จำนวนครั้งที่อ่านเจอ/ไม่เจอใน cache ตั้งแต่เริ่มโปรแกรม
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// CachedBookService BookService ที่อ่าน GetAll/ListVersion/GetByID ผ่าน cache ก่อน
+// Create/Update/Delete ส่งต่อให้ service จริงแล้วลบ key ที่เกี่ยวข้องทิ้ง (เล่มที่เพิ่ง Create/Update เขียนลง cache เลย)
+// ภายใน ttl หลังการเขียน ค่าที่โหลดเข้า cache อ่านจาก primary — replica ที่ตามไม่ทันจะไม่ถูกเก็บค้างไว้ทั้ง TTL
+type CachedBookService interface {
+	BookService
+	Stats() CacheStats
+}
+
+type cachedBookService struct {
+	next  BookService
+	cache cache.Cache
+	ttl   time.Duration
+
+	// loads รวม miss ของ key เดียวกันที่มาพร้อมกันให้อ่านแหล่งจริงครั้งเดียว (singleflight)
+	loads singleflight.Group
+	// generation เพิ่มทุกครั้งที่เขียน — ผลอ่านที่เริ่มก่อนการเขียนจะไม่ถูกเก็บลง cache (กันค่าเก่าค้าง)
+	// storeMutex ทำให้ "เทียบ generation แล้ว Set" กับ "เพิ่ม generation แล้ว Delete" ไม่แทรกกัน
+	generation atomic.Uint64
+	storeMutex sync.Mutex
+	// lastWrite เวลาเขียนล่าสุด (UnixNano) — ภายใน ttl หลังจากนั้น การโหลดเข้า cache อ่านจาก primary
+	lastWrite atomic.Int64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewCachedBookService ห่อ next ด้วย cache (entry อายุ ttl)
+func NewCachedBookService(next BookService, backend cache.Cache, ttl time.Duration) CachedBookService {
+	return &cachedBookService{next: next, cache: backend, ttl: ttl}
+}
+
+func (serviceImpl *cachedBookService) Stats() CacheStats {
+	return CacheStats{Hits: serviceImpl.hits.Load(), Misses: serviceImpl.misses.Load()}
+}
+
+// cachedList รายการกับเวอร์ชันเก็บเป็น entry เดียวกัน — ETag ที่ handler ทำจาก ListVersion จึงตรงกับ body จาก GetAll
+// (ถ้าเวอร์ชันสดจาก DB แต่รายการค้างใน cache client จะเก็บรายการเก่าไว้ใต้ ETag ใหม่ แล้วได้ 304 ไปเรื่อยๆ)
+type cachedList struct {
+	Version ListVersion   `json:"version"`
+	Books   []models.Book `json:"books"`
+}
+
+func (serviceImpl *cachedBookService) GetAll(ctx context.Context) ([]models.Book, error) {
+	list, err := serviceImpl.list(ctx)
+	return list.Books, err
+}
+
+// ListVersion อ่านจาก entry เดียวกับ GetAll (miss = โหลดทั้งเวอร์ชันและรายการ ซึ่ง GetAll ที่ตามมาต้องใช้อยู่แล้ว)
+func (serviceImpl *cachedBookService) ListVersion(ctx context.Context) (ListVersion, error) {
+	list, err := serviceImpl.list(ctx)
+	return list.Version, err
+}
+
+// list อ่านเวอร์ชันก่อนรายการ — มีการเขียนแทรกระหว่างสองคำสั่ง รายการจะใหม่กว่าเวอร์ชัน (ETag เก่ากว่า body)
+// ซึ่งแค่ทำให้ client โหลดซ้ำครั้งหน้า ไม่ใช่ได้ 304 ทั้งที่ข้อมูลเปลี่ยน
+func (serviceImpl *cachedBookService) list(ctx context.Context) (cachedList, error) {
+	var list cachedList
+	err := serviceImpl.readThrough(ctx, bookCacheListKey, &list, func(ctx context.Context) (any, error) {
+		version, err := serviceImpl.next.ListVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		books, err := serviceImpl.next.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return cachedList{Version: version, Books: books}, nil
+	})
+	return list, err
+}
+
+func (serviceImpl *cachedBookService) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	var book models.Book
+	err := serviceImpl.readThrough(ctx, bookCacheKey(bookID), &book, func(ctx context.Context) (any, error) {
+		return serviceImpl.next.GetByID(ctx, bookID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (serviceImpl *cachedBookService) Create(ctx context.Context, request dto.CreateBookRequest) (*models.Book, error) {
+	book, err := serviceImpl.next.Create(ctx, request)
+	if err == nil {
+		generation := serviceImpl.invalidate(ctx, bookCacheListKey)
+		serviceImpl.writeThrough(ctx, book, generation)
+	}
+	return book, err
+}
+
+func (serviceImpl *cachedBookService) Update(ctx context.Context, bookID uint, request dto.UpdateBookRequest) (*models.Book, error) {
+	book, err := serviceImpl.next.Update(ctx, bookID, request)
+	if err == nil {
+		generation := serviceImpl.invalidate(ctx, bookCacheListKey, bookCacheKey(bookID))
+		serviceImpl.writeThrough(ctx, book, generation)
+	}
+	return book, err
+}
+
+func (serviceImpl *cachedBookService) Delete(ctx context.Context, bookID uint) error {
+	err := serviceImpl.next.Delete(ctx, bookID)
+	if err == nil {
+		serviceImpl.invalidate(ctx, bookCacheListKey, bookCacheKey(bookID))
+	}
+	return err
+}
+
+// readThrough อ่าน key จาก cache ลง target ถ้าไม่เจอให้ load แล้วเก็บผลไว้
+// miss ที่มาพร้อมกันรอผลจาก load ตัวเดียวกัน — load ใช้ ctx ที่ไม่ถูกยกเลิกตามคนเรียกคนใด (จำกัดเวลาเองด้วย cacheLoadTimeout)
+// ส่วนแต่ละคนเลิกรอได้ตาม ctx ของตัวเอง: คนแรกตัดการเชื่อมต่อ คนที่รออยู่ยังได้ผลตามปกติ
+// cache พัง (Get/Set error) ไม่ทำให้ request ล้ม — log แล้วอ่านแหล่งจริงแทน
+// error จาก load (เช่น ไม่พบ) ไม่ถูกเก็บ
+func (serviceImpl *cachedBookService) readThrough(ctx context.Context, key string, target any, load func(ctx context.Context) (any, error)) error {
+	if cached, found, err := serviceImpl.cache.Get(ctx, key); err != nil {
+		logger.WarnfContext(ctx, "cache", "get %s failed: %v", key, err)
+	} else if found && json.Unmarshal(cached, target) == nil {
+		serviceImpl.hits.Add(1)
+		return nil
+	}
+	serviceImpl.misses.Add(1)
+
+	results := serviceImpl.loads.DoChan(key, func() (any, error) {
+		loadContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheLoadTimeout)
+		defer cancel()
+		generation := serviceImpl.generation.Load()
+		if serviceImpl.recentlyWritten() {
+			loadContext = repository.WithReadYourWrites(loadContext)
+			repository.PinPrimary(loadContext)
+		}
+		value, err := load(loadContext)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		serviceImpl.store(loadContext, key, encoded, generation)
+		return encoded, nil
+	})
+	select {
+	case <-ctx.Done():
+		return contextError("wait for "+key, ctx.Err())
+	case result := <-results:
+		if result.Err != nil {
+			return result.Err
+		}
+		return json.Unmarshal(result.Val.([]byte), target)
+	}
+}
+
+// contextError ห่อ error ของ ctx ให้ handler ตอบ 499/504 เหมือน error จาก repository
+func contextError(operation string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperr.Wrap(apperr.Timeout, operation, err)
+	}
+	return apperr.Wrap(apperr.Canceled, operation, err)
+}
+
+// store เก็บ encoded ลง cache เฉพาะเมื่อไม่มีการเขียนเกิดขึ้นหลังอ่าน generation มา
+func (serviceImpl *cachedBookService) store(ctx context.Context, key string, encoded []byte, generation uint64) {
+	serviceImpl.storeMutex.Lock()
+	defer serviceImpl.storeMutex.Unlock()
+	if serviceImpl.generation.Load() != generation {
+		return
+	}
+	if err := serviceImpl.cache.Set(ctx, key, encoded, serviceImpl.ttl); err != nil {
+		logger.WarnfContext(ctx, "cache", "set %s failed: %v", key, err)
+	}
+}
+
+// invalidate ลบ keys แล้วคืน generation ใหม่ (ใช้กับ writeThrough ของการเขียนครั้งนี้)
+func (serviceImpl *cachedBookService) invalidate(ctx context.Context, keys ...string) uint64 {
+	serviceImpl.storeMutex.Lock()
+	defer serviceImpl.storeMutex.Unlock()
+	generation := serviceImpl.generation.Add(1)
+	serviceImpl.lastWrite.Store(time.Now().UnixNano())
+	// การเขียนสำเร็จไปแล้ว — client ตัดการเชื่อมต่อตอนนี้ก็ต้องลบ key ให้ได้ ไม่เช่นนั้นค่าเก่าค้างจนหมด TTL
+	if err := serviceImpl.cache.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		logger.WarnfContext(ctx, "cache", "invalidate %v failed: %v", keys, err)
+	}
+	return generation
+}
+
+// writeThrough เก็บเล่มที่ service จริงเพิ่งเขียนคืนมา (ค่าล่าสุดจาก primary) — GetByID ถัดไปไม่ต้องอ่าน replica ที่อาจยังตามไม่ทัน
+// มีการเขียนอื่นแทรกหลัง invalidate ของเรา = ค่านี้อาจเก่าแล้ว ไม่เก็บ
+func (serviceImpl *cachedBookService) writeThrough(ctx context.Context, book *models.Book, generation uint64) {
+	encoded, err := json.Marshal(book)
+	if err != nil {
+		return
+	}
+	serviceImpl.store(context.WithoutCancel(ctx), bookCacheKey(book.ID), encoded, generation)
+}
+
+// recentlyWritten ยังอยู่ภายใน ttl หลังการเขียนล่าสุด (ttl <= 0 = entry ไม่หมดอายุ จึงนับว่าใช่เสมอหลังมีการเขียน)
+func (serviceImpl *cachedBookService) recentlyWritten() bool {
+	lastWrite := serviceImpl.lastWrite.Load()
+	if lastWrite == 0 {
+		return false
+	}
+	return serviceImpl.ttl <= 0 || time.Since(time.Unix(0, lastWrite)) < serviceImpl.ttl
+}
+
+func bookCacheKey(bookID uint) string {
+	return bookCacheKeyPrefix + strconv.FormatUint(uint64(bookID), 10)
+}
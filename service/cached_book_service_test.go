@@ -0,0 +1,358 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/database/dbtest"
+	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/cache"
+	"github.com/nuba55yo/go-101-BasicCRUD/repository"
+	"github.com/nuba55yo/go-101-BasicCRUD/service"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// อีก instance เขียนโดยไม่ผ่าน cache ของเรา (cache ใน process ไม่ถูกลบ): ETag ต้องยังตรงกับรายการที่ตอบ
+// ไม่เช่นนั้น client จะเก็บรายการเก่าไว้ใต้ ETag ใหม่แล้วได้ 304 ตลอด
+func TestCachedListVersionMatchesCachedList(t *testing.T) {
+	ctx := context.Background()
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	cachedService := service.NewCachedBookService(bookService, cache.NewLRU(10), time.Hour)
+
+	if _, err := cachedService.Create(ctx, dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedService.GetAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bookService.Create(ctx, dto.CreateBookRequest{Title: "Emma", Author: "Jane Austen"}); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := cachedService.ListVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	books, err := cachedService.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Count != int64(len(books)) {
+		t.Fatalf("version count = %d but list has %d books", version.Count, len(books))
+	}
+
+	// เขียนผ่าน cache → ทั้งสองค่าเปลี่ยนพร้อมกัน
+	if _, err := cachedService.Create(ctx, dto.CreateBookRequest{Title: "Gone", Author: "Author"}); err != nil {
+		t.Fatal(err)
+	}
+	version, err = cachedService.ListVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	books, err = cachedService.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Count != 3 || len(books) != 3 {
+		t.Fatalf("after write: version count = %d, list = %d; want 3 and 3", version.Count, len(books))
+	}
+}
+
+// blockingBookService GetByID รอจนกว่า release จะถูกปิด แล้วนับจำนวนครั้งที่ถูกเรียกจริง
+type blockingBookService struct {
+	service.BookService
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+// อ่านค่าก่อนรอ — ผลที่คืนจึงเป็นค่า ณ ตอนเริ่มโหลด (ใช้จำลองการอ่านที่คร่อมการเขียน)
+func (blocking *blockingBookService) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	book, err := blocking.BookService.GetByID(ctx, bookID)
+	if blocking.calls.Add(1) == 1 {
+		close(blocking.started)
+	}
+	<-blocking.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return book, err
+}
+
+// คนที่เริ่มโหลดยกเลิก ctx ระหว่างรอ: ตัวเองได้ Canceled แต่คนที่รอผลเดียวกันยังได้หนังสือ (และผลถูกเก็บลง cache)
+func TestCachedGetByIDSurvivesFirstCallerCancel(t *testing.T) {
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	book, err := bookService.Create(context.Background(), dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocking := &blockingBookService{BookService: bookService, started: make(chan struct{}), release: make(chan struct{})}
+	cachedService := service.NewCachedBookService(blocking, cache.NewLRU(10), time.Hour)
+
+	firstContext, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cachedService.GetByID(firstContext, book.ID)
+		firstErr <- err
+	}()
+	<-blocking.started
+	secondResult := make(chan error, 1)
+	go func() {
+		_, err := cachedService.GetByID(context.Background(), book.ID)
+		secondResult <- err
+	}()
+
+	cancelFirst()
+	select {
+	case err := <-firstErr:
+		if !apperr.IsKind(err, apperr.Canceled) {
+			t.Fatalf("first caller error = %v, want Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("first caller still waiting for the shared load after its ctx was canceled")
+	}
+	close(blocking.release)
+	if err := <-secondResult; err != nil {
+		t.Fatalf("second caller error = %v, want the shared result", err)
+	}
+	if calls := blocking.calls.Load(); calls != 1 {
+		t.Errorf("loads = %d, want 1", calls)
+	}
+	if _, err := cachedService.GetByID(context.Background(), book.ID); err != nil || blocking.calls.Load() != 1 {
+		t.Errorf("after load: err = %v loads = %d, want cached hit", err, blocking.calls.Load())
+	}
+}
+
+// laggingReader ส่งทุกการอ่านไป replica ที่ไม่เคยได้รับการเขียนใดๆ (ตามไม่ทันตลอด)
+type laggingReader struct {
+	replica *gorm.DB
+}
+
+func (reader laggingReader) Reader() *gorm.DB            { return reader.replica }
+func (laggingReader) ReplicaFailed(*gorm.DB, error) bool { return false }
+
+// หลังเขียนผ่าน cache ค่าที่ถูกเก็บต้องมาจาก primary ไม่ใช่ replica ที่ยังเห็นข้อมูลก่อนเขียน
+func TestCachedReadsAfterWriteIgnoreReplicaLag(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := dbtest.OpenSQLite(t).DB(), dbtest.OpenSQLite(t).DB()
+	original := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	for _, db := range []*gorm.DB{primary, replica} {
+		book := *original
+		if err := db.Create(&book).Error; err != nil {
+			t.Fatal(err)
+		}
+		original.ID = book.ID
+	}
+	bookService := service.NewBookService(repository.NewBookRepository(primary, laggingReader{replica}), repository.NewTxManager(primary))
+	cachedService := service.NewCachedBookService(bookService, cache.NewLRU(10), time.Hour)
+
+	if _, err := cachedService.Update(ctx, original.ID, dto.UpdateBookRequest{Title: "Dune Messiah", Author: "Frank Herbert"}); err != nil {
+		t.Fatal(err)
+	}
+	book, err := cachedService.GetByID(ctx, original.ID)
+	if err != nil || book.Title != "Dune Messiah" {
+		t.Fatalf("GetByID after update = %+v, %v; want Dune Messiah", book, err)
+	}
+	books, err := cachedService.GetAll(ctx)
+	if err != nil || len(books) != 1 || books[0].Title != "Dune Messiah" {
+		t.Fatalf("GetAll after update = %+v, %v; want [Dune Messiah]", books, err)
+	}
+
+	if err := cachedService.Delete(ctx, original.ID); err != nil {
+		t.Fatal(err)
+	}
+	if book, err := cachedService.GetByID(ctx, original.ID); !apperr.IsKind(err, apperr.NotFound) {
+		t.Fatalf("GetByID after delete = %+v, %v; want NotFound", book, err)
+	}
+	if books, err := cachedService.GetAll(ctx); err != nil || len(books) != 0 {
+		t.Fatalf("GetAll after delete = %+v, %v; want empty", books, err)
+	}
+}
+
+// countingBookService นับจำนวนครั้งที่อ่านถึง service จริง
+type countingBookService struct {
+	service.BookService
+	getAll, getByID atomic.Int32
+}
+
+func (counting *countingBookService) GetAll(ctx context.Context) ([]models.Book, error) {
+	counting.getAll.Add(1)
+	return counting.BookService.GetAll(ctx)
+}
+
+func (counting *countingBookService) GetByID(ctx context.Context, bookID uint) (*models.Book, error) {
+	counting.getByID.Add(1)
+	return counting.BookService.GetByID(ctx, bookID)
+}
+
+func newCountingService(t *testing.T) (*countingBookService, service.CachedBookService) {
+	t.Helper()
+	counting := &countingBookService{BookService: service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())}
+	return counting, service.NewCachedBookService(counting, cache.NewLRU(10), time.Hour)
+}
+
+func TestCachedStatsCountHitsAndMisses(t *testing.T) {
+	ctx := context.Background()
+	counting, cachedService := newCountingService(t)
+	book, err := counting.Create(ctx, dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if _, err := cachedService.GetByID(ctx, book.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// GetAll กับ ListVersion ใช้ entry เดียวกัน: miss ครั้งเดียวแล้ว hit
+	if _, err := cachedService.GetAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedService.ListVersion(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// ไม่พบไม่ถูกเก็บ: miss ทุกครั้ง
+	for range 2 {
+		if _, err := cachedService.GetByID(ctx, book.ID+1); !apperr.IsKind(err, apperr.NotFound) {
+			t.Fatalf("GetByID(missing) error = %v, want NotFound", err)
+		}
+	}
+
+	if stats := cachedService.Stats(); stats != (service.CacheStats{Hits: 3, Misses: 4}) {
+		t.Errorf("Stats = %+v, want 3 hits and 4 misses", stats)
+	}
+	if calls := counting.getByID.Load(); calls != 3 {
+		t.Errorf("GetByID reached the service %d times, want 3", calls)
+	}
+}
+
+// miss ของ key เดียวกันพร้อมกันหลายตัวโหลดครั้งเดียว (ตัวที่มาหลังโหลดเสร็จได้ hit แทน)
+func TestCachedGetByIDCollapsesConcurrentMisses(t *testing.T) {
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	book, err := bookService.Create(context.Background(), dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocking := &blockingBookService{BookService: bookService, started: make(chan struct{}), release: make(chan struct{})}
+	cachedService := service.NewCachedBookService(blocking, cache.NewLRU(10), time.Hour)
+
+	const callers = 20
+	var group sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			got, err := cachedService.GetByID(context.Background(), book.ID)
+			if err == nil && got.Title != "Dune" {
+				err = fmt.Errorf("title = %q, want Dune", got.Title)
+			}
+			errs <- err
+		}()
+	}
+	<-blocking.started
+	close(blocking.release)
+	group.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if calls := blocking.calls.Load(); calls != 1 {
+		t.Errorf("loads = %d, want 1", calls)
+	}
+	if stats := cachedService.Stats(); stats.Hits+stats.Misses != callers {
+		t.Errorf("Stats = %+v, want %d reads in total", stats, callers)
+	}
+}
+
+func TestCachedWritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	counting, cachedService := newCountingService(t)
+	getAll := func(want int) {
+		t.Helper()
+		books, err := cachedService.GetAll(ctx)
+		if err != nil || len(books) != want {
+			t.Fatalf("GetAll = %d books, %v; want %d", len(books), err, want)
+		}
+	}
+
+	getAll(0)
+	getAll(0)
+	if calls := counting.getAll.Load(); calls != 1 {
+		t.Fatalf("GetAll loads = %d, want 1 before any write", calls)
+	}
+
+	book, err := cachedService.Create(ctx, dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	getAll(1)
+	// เล่มที่เพิ่งสร้างถูกเขียนลง cache แล้ว ไม่ต้องโหลด
+	if got, err := cachedService.GetByID(ctx, book.ID); err != nil || got.Title != "Dune" {
+		t.Fatalf("GetByID after create = %+v, %v", got, err)
+	}
+
+	if _, err := cachedService.Update(ctx, book.ID, dto.UpdateBookRequest{Title: "Dune Messiah", Author: "Frank Herbert"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cachedService.GetByID(ctx, book.ID); err != nil || got.Title != "Dune Messiah" {
+		t.Fatalf("GetByID after update = %+v, %v; want Dune Messiah", got, err)
+	}
+	getAll(1)
+
+	if err := cachedService.Delete(ctx, book.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedService.GetByID(ctx, book.ID); !apperr.IsKind(err, apperr.NotFound) {
+		t.Fatalf("GetByID after delete error = %v, want NotFound", err)
+	}
+	getAll(0)
+
+	if calls := counting.getAll.Load(); calls != 4 {
+		t.Errorf("GetAll loads = %d, want 4 (initial + one after each write)", calls)
+	}
+	if calls := counting.getByID.Load(); calls != 1 {
+		t.Errorf("GetByID loads = %d, want 1 (only after delete)", calls)
+	}
+}
+
+// โหลดที่เริ่มก่อน Update แต่จบหลัง ต้องไม่ทับค่าใหม่ใน cache ด้วยค่าเก่าที่อ่านมา
+func TestCachedLoadStartedBeforeWriteIsNotStored(t *testing.T) {
+	ctx := context.Background()
+	bookService := service.NewBookService(repository.NewMemoryBookRepository(), repository.NewMemoryTxManager())
+	book, err := bookService.Create(ctx, dto.CreateBookRequest{Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocking := &blockingBookService{BookService: bookService, started: make(chan struct{}), release: make(chan struct{})}
+	cachedService := service.NewCachedBookService(blocking, cache.NewLRU(10), time.Hour)
+
+	inFlight := make(chan *models.Book, 1)
+	go func() {
+		got, _ := cachedService.GetByID(ctx, book.ID)
+		inFlight <- got
+	}()
+	<-blocking.started
+	if _, err := cachedService.Update(ctx, book.ID, dto.UpdateBookRequest{Title: "Dune Messiah", Author: "Frank Herbert"}); err != nil {
+		t.Fatal(err)
+	}
+	close(blocking.release)
+	if got := <-inFlight; got == nil || got.Title != "Dune" {
+		t.Fatalf("in-flight read = %+v, want the value it loaded (Dune)", got)
+	}
+
+	got, err := cachedService.GetByID(ctx, book.ID)
+	if err != nil || got.Title != "Dune Messiah" {
+		t.Fatalf("GetByID after update = %+v, %v; want Dune Messiah", got, err)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nuba55yo/go-101-BasicCRUD/dto"
+	"github.com/nuba55yo/go-101-BasicCRUD/models"
+	"github.com/nuba55yo/go-101-BasicCRUD/pkg/tracing"
+	"github.com/nuba55yo/go-101-BasicCRUD/service/apperr"
+)
+
+// tracedBookService สร้าง child span "BookService.<method>" รอบทุกเมธอดของ next
+// span ของ GORM ที่เกิดข้างในจะเป็นลูกของ span นี้อีกชั้น
+type tracedBookService struct {
+	next BookService
+}
+
+// NewTracedBookService ห่อ next ด้วย span ต่อเมธอด (ใช้ tracer จาก tracing.Setup)
+func NewTracedBookService(next BookService) BookService {
+	return &tracedBookService{next: next}
+}
+
+func (serviceImpl *tracedBookService) Create(ctx context.Context, request dto.CreateBookRequest) (book *models.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.Create", attribute.String("book.title", request.Title))
+	defer func() { endSpan(span, err) }()
+	book, err = serviceImpl.next.Create(ctx, request)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("book.id", int64(book.ID)))
+	}
+	return book, err
+}
+
+func (serviceImpl *tracedBookService) GetAll(ctx context.Context) (books []models.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetAll")
+	defer func() { endSpan(span, err) }()
+	books, err = serviceImpl.next.GetAll(ctx)
+	span.SetAttributes(attribute.Int("book.count", len(books)))
+	return books, err
+}
+
+func (serviceImpl *tracedBookService) ListVersion(ctx context.Context) (version ListVersion, err error) {
+	ctx, span := startSpan(ctx, "BookService.ListVersion")
+	defer func() { endSpan(span, err) }()
+	return serviceImpl.next.ListVersion(ctx)
+}
+
+func (serviceImpl *tracedBookService) GetByID(ctx context.Context, bookID uint) (book *models.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetByID", attribute.Int64("book.id", int64(bookID)))
+	defer func() { endSpan(span, err) }()
+	return serviceImpl.next.GetByID(ctx, bookID)
+}
+
+func (serviceImpl *tracedBookService) Update(ctx context.Context, bookID uint, request dto.UpdateBookRequest) (book *models.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.Update", attribute.Int64("book.id", int64(bookID)), attribute.String("book.title", request.Title))
+	defer func() { endSpan(span, err) }()
+	return serviceImpl.next.Update(ctx, bookID, request)
+}
+
+func (serviceImpl *tracedBookService) Delete(ctx context.Context, bookID uint) (err error) {
+	ctx, span := startSpan(ctx, "BookService.Delete", attribute.Int64("book.id", int64(bookID)))
+	defer func() { endSpan(span, err) }()
+	return serviceImpl.next.Delete(ctx, bookID)
+}
+
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan บันทึกผลแล้วปิด span — error ธุรกิจ (ไม่พบ/ชื่อซ้ำ/input ผิด) ไม่นับเป็น span error
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		kind := apperr.KindOf(err)
+		span.SetAttributes(attribute.String("error.kind", kind.String()))
+		switch kind {
+		case apperr.NotFound, apperr.Conflict, apperr.Validation:
+		default:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}